@@ -7,14 +7,12 @@ import (
 )
 
 type Assets struct {
-	TitleImage    *ebiten.Image
-	TitleImageNYC *ebiten.Image
-	BikerImage    *ebiten.Image
-	PeopleImage   *ebiten.Image
-	TilesetImage  *ebiten.Image
+	TitleImage  *ebiten.Image
+	BikerImage  *ebiten.Image
+	PeopleImage *ebiten.Image
 
 	// future
-	// NYCSpriteSheet *sprites.AsepriteSheet //??? Have it as TilesetImage now
+	// NYCSpriteSheet *sprites.AsepriteSheet //??? Each Level loads its own Tileset now (levels.go)
 }
 
 // NOTE: LOOK AT EMBED.GO to embed this files in so WASM works!
@@ -24,11 +22,6 @@ func LoadAssets() *Assets {
 		log.Fatal(err)
 	}
 
-	nycTitle, err := loadImage("art/aseprite_files/nyc_title.png")
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	biker, err := loadImage("art/aseprite_files/biker.png")
 	if err != nil {
 		log.Fatal(err)
@@ -39,16 +32,9 @@ func LoadAssets() *Assets {
 		log.Fatal(err)
 	}
 
-	tileset, err := loadImage("assets/NEW_nyc_spritesheet-Recovered.png")
-	if err != nil {
-		log.Fatal(err) // Changed to log.Fatal to match your other assets
-	}
-
 	return &Assets{
-		TitleImage:    title,
-		TitleImageNYC: nycTitle,
-		BikerImage:    biker,
-		PeopleImage:   people,
-		TilesetImage:  tileset,
+		TitleImage:  title,
+		BikerImage:  biker,
+		PeopleImage: people,
 	}
 }
@@ -12,6 +12,9 @@ type Camera struct {
 	ViewportW int
 	ViewportH int
 
+	// World rectangle: top-left corner and size (infinite maps can start below zero)
+	WorldX int
+	WorldY int
 	WorldW int
 	WorldH int
 
@@ -45,15 +48,15 @@ func (c *Camera) Follow(x, y float64) {
 }
 
 func (c *Camera) clamp() {
-	if c.X < 0 {
-		c.X = 0
+	if c.X < float64(c.WorldX) {
+		c.X = float64(c.WorldX)
 	}
-	if c.Y < 0 {
-		c.Y = 0
+	if c.Y < float64(c.WorldY) {
+		c.Y = float64(c.WorldY)
 	}
 
-	maxX := float64(c.WorldW - c.ViewportW)
-	maxY := float64(c.WorldH - c.ViewportH)
+	maxX := float64(c.WorldX + c.WorldW - c.ViewportW)
+	maxY := float64(c.WorldY + c.WorldH - c.ViewportH)
 
 	if c.X > maxX {
 		c.X = maxX
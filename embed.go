@@ -8,9 +8,14 @@ import (
 	"embed"
 	"encoding/json"
 	"image"
+	"image/color"
+	"image/draw"
 	_ "image/png"
+	"io/fs"
+	"path"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/ngolebiewski/alley_cat_1999/tiled"
 )
 
 //go:embed art/ac99_title.png
@@ -19,8 +24,8 @@ import (
 //go:embed art/aseprite_files/people.png
 //go:embed art/aseprite_files/biker.json
 //go:embed assets/NEW_nyc_spritesheet-Recovered.png
-//go:embed assets/nyc_1..tmj
 //go:embed assets/**
+//go:embed art/tiled/*.tx art/tiled/*.tsx art/tiled/*.tmx
 
 // could embed the entire directory with 'art/**' but there are files I don't want in there to keep the build small.
 // For example. ASEPRITE files with layers, and unused artworks or test files.
@@ -28,17 +33,20 @@ import (
 var embeddedAssets embed.FS
 
 func loadImage(path string) (*ebiten.Image, error) {
-	data, err := embeddedAssets.ReadFile(path)
+	img, err := decodeImage(path)
 	if err != nil {
 		return nil, err
 	}
+	return ebiten.NewImageFromImage(img), nil
+}
 
-	img, _, err := image.Decode(bytes.NewReader(data))
+func decodeImage(path string) (image.Image, error) {
+	data, err := embeddedAssets.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-
-	return ebiten.NewImageFromImage(img), nil
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
 }
 
 func loadJSON(path string, v any) error {
@@ -48,3 +56,47 @@ func loadJSON(path string, v any) error {
 	}
 	return json.Unmarshal(data, v)
 }
+
+// loadTilesetImages loads the image of every tileset the map uses, in the same order as m.Tilesets.
+// The paths come from the .tsx files as the artist saved them (art/aseprite_files/...), those
+// sheets aren't embedded so the copy with the same name in assets/ is used, see tilesetImagePath.
+// A tileset's transparent color (trans="ff00ff") is keyed out, like Tiled does.
+func loadTilesetImages(m *tiled.Map) ([]*ebiten.Image, error) {
+	images := make([]*ebiten.Image, len(m.Tilesets))
+	for i, ts := range m.Tilesets {
+		img, err := decodeImage(tilesetImagePath(embeddedAssets, ts.Image))
+		if err != nil {
+			return nil, err
+		}
+		if ts.TransparentColor != "" {
+			key, err := tiled.ParseColor(ts.TransparentColor)
+			if err != nil {
+				return nil, err
+			}
+			img = colorKey(img, key)
+		}
+		images[i] = ebiten.NewImageFromImage(img)
+	}
+	return images, nil
+}
+
+// tilesetImagePath is p when it's embedded, otherwise the file with the same name in assets/
+func tilesetImagePath(fsys fs.FS, p string) string {
+	if _, err := fs.Stat(fsys, p); err == nil {
+		return p
+	}
+	return path.Join("assets", path.Base(p))
+}
+
+// colorKey returns a copy of img with every pixel of the key color made transparent
+func colorKey(img image.Image, key color.RGBA) image.Image {
+	out := image.NewNRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+	for i := 0; i < len(out.Pix); i += 4 {
+		p := out.Pix[i : i+4]
+		if p[0] == key.R && p[1] == key.G && p[2] == key.B {
+			p[0], p[1], p[2], p[3] = 0, 0, 0, 0
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTilesetImagePath(t *testing.T) {
+	fsys := fstest.MapFS{
+		"art/tiles/city.png":  {},
+		"assets/harbor.png":   {},
+		"assets/sheet_v2.png": {},
+	}
+	tests := []struct{ in, want string }{
+		{"art/tiles/city.png", "art/tiles/city.png"},           // embedded where the .tsx says
+		{"art/aseprite_files/harbor.png", "assets/harbor.png"}, // the copy in assets/
+		{"art/aseprite_files/missing.png", "assets/missing.png"},
+	}
+	for _, tt := range tests {
+		if got := tilesetImagePath(fsys, tt.in); got != tt.want {
+			t.Errorf("tilesetImagePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// Every stage's tilesets have to end up at an image that's actually embedded
+func TestLevelTilesetImagesEmbedded(t *testing.T) {
+	for _, l := range campaign {
+		m, err := l.LoadMap()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ts := range m.Tilesets {
+			p := tilesetImagePath(embeddedAssets, ts.Image)
+			if _, err := fs.Stat(embeddedAssets, p); err != nil {
+				t.Errorf("%s: tileset %s image %s: %v", l.ID, ts.Name, p, err)
+			}
+		}
+	}
+}
+
+func TestColorKey(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	src.Set(0, 0, color.NRGBA{255, 0, 255, 255}) // the key
+	src.Set(1, 0, color.NRGBA{255, 0, 254, 255}) // close isn't the key
+	src.Set(2, 0, color.NRGBA{10, 20, 30, 128})
+
+	out := colorKey(src, color.RGBA{255, 0, 255, 255})
+	want := []color.NRGBA{{}, {255, 0, 254, 255}, {10, 20, 30, 128}}
+	for x, w := range want {
+		if got := color.NRGBAModel.Convert(out.At(x, 0)); got != w {
+			t.Errorf("pixel %d = %v, want %v", x, got, w)
+		}
+	}
+	if src.NRGBAAt(0, 0).A != 255 {
+		t.Error("colorKey changed its input")
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Scenes ask for actions ("is Mount just pressed?") instead of keys, so the keyboard,
+// a gamepad and the touch controls all work everywhere. bindings says what triggers
+// what. Game.input is the real thing (DeviceInput), ScriptedInput replays a script.
+
+// Action is something the player does
+type Action int
+
+const (
+	ActionSteer      Action = iota // arrows, d-pad, left stick or the virtual joystick, read with Steer
+	ActionToggleAxis               // flip between horizontal and vertical riding
+	ActionMount                    // on/off the bike
+	ActionPause
+	ActionConfirm // menus and cards, clicks and taps come separately through Taps
+	ActionBack
+	ActionEnterCode  // start typing a manifest code
+	ActionErase      // take back the last typed character
+	ActionFullscreen // global, see Game.Update
+	ActionDebug      // global, see Game.Update
+)
+
+// Input is what the scenes read, Update runs once a tick before the scene does
+type Input interface {
+	Update()
+	Pressed(a Action) bool     // held down
+	JustPressed(a Action) bool // went down this tick
+	Steer() (x, y float64)     // -1..1 each way, 0,0 when nobody's steering
+	Taps() []Tap               // clicks and touches that started this tick
+	Joystick() *joystick       // the virtual joystick for drawing, nil if there isn't one
+}
+
+// Tap is a click or a touch, in screen pixels
+type Tap struct {
+	X, Y  int
+	Touch bool
+}
+
+// Binding is everything that triggers an action
+type Binding struct {
+	Keys    []ebiten.Key
+	Buttons []ebiten.StandardGamepadButton
+	Touch   []string // virtual buttons, see touchButtons
+}
+
+var bindings = map[Action]Binding{
+	ActionToggleAxis: {
+		Keys:    []ebiten.Key{ebiten.KeySpace},
+		Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightBottom}, // A / cross
+		Touch:   []string{"A"},
+	},
+	ActionMount: {
+		Keys:    []ebiten.Key{ebiten.KeyB},
+		Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightRight}, // B / circle
+		Touch:   []string{"B"},
+	},
+	ActionPause: {
+		Keys:    []ebiten.Key{ebiten.KeyEnter},
+		Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonCenterRight}, // start
+		Touch:   []string{"PAUSE"},
+	},
+	ActionConfirm: {
+		Keys:    []ebiten.Key{ebiten.KeySpace, ebiten.KeyEnter},
+		Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightBottom, ebiten.StandardGamepadButtonCenterRight},
+	},
+	ActionBack: {
+		Keys:    []ebiten.Key{ebiten.KeyEscape},
+		Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonCenterLeft}, // back / select
+	},
+	ActionEnterCode:  {Keys: []ebiten.Key{ebiten.KeyC}},
+	ActionErase:      {Keys: []ebiten.Key{ebiten.KeyBackspace}},
+	ActionFullscreen: {Keys: []ebiten.Key{ebiten.KeyF}},
+	ActionDebug:      {Keys: []ebiten.Key{ebiten.KeyD}},
+}
+
+// Steering, the same four ways on the keyboard and the d-pad
+var steerKeys = [4]ebiten.Key{ebiten.KeyLeft, ebiten.KeyRight, ebiten.KeyUp, ebiten.KeyDown}
+
+var steerButtons = [4]ebiten.StandardGamepadButton{
+	ebiten.StandardGamepadButtonLeftLeft,
+	ebiten.StandardGamepadButtonLeftRight,
+	ebiten.StandardGamepadButtonLeftTop,
+	ebiten.StandardGamepadButtonLeftBottom,
+}
+
+// steerDeadzone is how far a stick has to go before it counts
+const steerDeadzone = 0.2
+
+// touchButtons are the virtual buttons' hit rects, drawn by RaceScene.drawMobileUI
+var touchButtons = map[string]image.Rectangle{
+	"A":     image.Rect(270*zoom, 170*zoom, 310*zoom, 210*zoom), // Centered at 290, radius 20
+	"B":     image.Rect(220*zoom, 170*zoom, 260*zoom, 210*zoom), // Centered at 240, radius 20
+	"PAUSE": image.Rect(130*zoom, 210*zoom, 190*zoom, 235*zoom), // the START button
+}
+
+// DeviceInput reads the keyboard, mouse, standard layout gamepads and touches
+type DeviceInput struct {
+	stick    joystick
+	pads     []ebiten.GamepadID
+	touchIDs []ebiten.TouchID
+	taps     []Tap
+
+	steerX, steerY float64
+	steering       bool
+	wasSteering    bool
+}
+
+func NewDeviceInput() *DeviceInput {
+	return &DeviceInput{}
+}
+
+func (in *DeviceInput) Update() {
+	in.pads = in.pads[:0]
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			in.pads = append(in.pads, id)
+		}
+	}
+
+	if isMobile {
+		in.stick.update()
+	} else {
+		in.stick.active = false
+	}
+
+	in.taps = in.taps[:0]
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButton0) {
+		x, y := ebiten.CursorPosition()
+		in.taps = append(in.taps, Tap{X: x, Y: y})
+	}
+	in.touchIDs = inpututil.AppendJustPressedTouchIDs(in.touchIDs[:0])
+	for _, id := range in.touchIDs {
+		x, y := ebiten.TouchPosition(id)
+		in.taps = append(in.taps, Tap{X: x, Y: y, Touch: true})
+	}
+
+	in.steerX, in.steerY = in.readSteer()
+	in.wasSteering = in.steering
+	in.steering = math.Abs(in.steerX) > steerDeadzone || math.Abs(in.steerY) > steerDeadzone
+}
+
+// readSteer adds up every way of steering, the keys and d-pad count as full tilt
+func (in *DeviceInput) readSteer() (float64, float64) {
+	var dir [4]bool
+	for i, k := range steerKeys {
+		dir[i] = ebiten.IsKeyPressed(k)
+	}
+	x, y := in.stick.vector()
+	for _, id := range in.pads {
+		for i, b := range steerButtons {
+			dir[i] = dir[i] || ebiten.IsStandardGamepadButtonPressed(id, b)
+		}
+		x += ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		y += ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	}
+	if dir[0] {
+		x--
+	}
+	if dir[1] {
+		x++
+	}
+	if dir[2] {
+		y--
+	}
+	if dir[3] {
+		y++
+	}
+	return math.Max(-1, math.Min(1, x)), math.Max(-1, math.Min(1, y))
+}
+
+func (in *DeviceInput) Pressed(a Action) bool {
+	if a == ActionSteer {
+		return in.steering
+	}
+	b := bindings[a]
+	for _, k := range b.Keys {
+		if ebiten.IsKeyPressed(k) {
+			return true
+		}
+	}
+	for _, id := range in.pads {
+		for _, btn := range b.Buttons {
+			if ebiten.IsStandardGamepadButtonPressed(id, btn) {
+				return true
+			}
+		}
+	}
+	for _, name := range b.Touch {
+		for _, id := range ebiten.TouchIDs() {
+			if in.touchIn(id, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (in *DeviceInput) JustPressed(a Action) bool {
+	if a == ActionSteer {
+		return in.steering && !in.wasSteering
+	}
+	b := bindings[a]
+	for _, k := range b.Keys {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	for _, id := range in.pads {
+		for _, btn := range b.Buttons {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, btn) {
+				return true
+			}
+		}
+	}
+	for _, name := range b.Touch {
+		for _, id := range in.touchIDs {
+			if in.touchIn(id, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// touchIn says if a touch is on a virtual button, they only show up on mobile
+func (in *DeviceInput) touchIn(id ebiten.TouchID, button string) bool {
+	x, y := ebiten.TouchPosition(id)
+	return isMobile && image.Pt(x, y).In(touchButtons[button])
+}
+
+func (in *DeviceInput) Steer() (float64, float64) { return in.steerX, in.steerY }
+
+func (in *DeviceInput) Taps() []Tap { return in.taps }
+
+func (in *DeviceInput) Joystick() *joystick {
+	if !isMobile {
+		return nil
+	}
+	return &in.stick
+}
+
+// InputFrame is one tick of scripted input
+type InputFrame struct {
+	Held           []Action
+	SteerX, SteerY float64
+	Taps           []Tap
+}
+
+// ScriptedInput plays back Frames, one a tick, then nothing at all.
+// For tests, attract mode demos and the like: game.input = &ScriptedInput{Frames: ...}
+type ScriptedInput struct {
+	Frames    []InputFrame
+	tick      int
+	prev, cur InputFrame
+}
+
+func (in *ScriptedInput) Update() {
+	in.prev = in.cur
+	in.cur = InputFrame{}
+	if in.tick < len(in.Frames) {
+		in.cur = in.Frames[in.tick]
+	}
+	in.tick++
+}
+
+func (in *ScriptedInput) Pressed(a Action) bool {
+	return in.cur.holds(a)
+}
+
+func (in *ScriptedInput) JustPressed(a Action) bool {
+	return in.cur.holds(a) && !in.prev.holds(a)
+}
+
+func (in *ScriptedInput) Steer() (float64, float64) { return in.cur.SteerX, in.cur.SteerY }
+
+func (in *ScriptedInput) Taps() []Tap { return in.cur.Taps }
+
+func (in *ScriptedInput) Joystick() *joystick { return nil }
+
+func (f InputFrame) holds(a Action) bool {
+	if a == ActionSteer {
+		return math.Abs(f.SteerX) > steerDeadzone || math.Abs(f.SteerY) > steerDeadzone
+	}
+	for _, h := range f.Held {
+		if h == a {
+			return true
+		}
+	}
+	return false
+}
+
+// menuFlick turns the tick steering starts into one step for menus, -1, 0 or 1 each way
+func menuFlick(in Input) (dx, dy int) {
+	if !in.JustPressed(ActionSteer) {
+		return 0, 0
+	}
+	x, y := in.Steer()
+	step := func(v float64) int {
+		switch {
+		case v < -steerDeadzone:
+			return -1
+		case v > steerDeadzone:
+			return 1
+		}
+		return 0
+	}
+	return step(x), step(y)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestScriptedInputJustPressed(t *testing.T) {
+	in := &ScriptedInput{Frames: []InputFrame{
+		{Held: []Action{ActionConfirm}},
+		{Held: []Action{ActionConfirm, ActionMount}},
+		{},
+		{Held: []Action{ActionConfirm}},
+	}}
+	var pressed, just, mount []bool
+	for range 6 { // two ticks past the script, which holds nothing
+		in.Update()
+		pressed = append(pressed, in.Pressed(ActionConfirm))
+		just = append(just, in.JustPressed(ActionConfirm))
+		mount = append(mount, in.JustPressed(ActionMount))
+	}
+	if want := []bool{true, true, false, true, false, false}; !slices.Equal(pressed, want) {
+		t.Errorf("Pressed = %v, want %v", pressed, want)
+	}
+	if want := []bool{true, false, false, true, false, false}; !slices.Equal(just, want) {
+		t.Errorf("JustPressed = %v, want %v", just, want)
+	}
+	if want := []bool{false, true, false, false, false, false}; !slices.Equal(mount, want) {
+		t.Errorf("JustPressed(Mount) = %v, want %v", mount, want)
+	}
+}
+
+func TestMenuFlick(t *testing.T) {
+	in := &ScriptedInput{Frames: []InputFrame{
+		{SteerX: 1},
+		{SteerX: 1}, // held, no repeat
+		{},
+		{SteerX: 0.1}, // inside the deadzone
+		{SteerY: -0.8},
+		{SteerX: -1, SteerY: -0.8}, // still steering, no new flick
+		{},
+		{SteerX: -0.5, SteerY: 0.5},
+	}}
+	want := [][2]int{{1, 0}, {0, 0}, {0, 0}, {0, 0}, {0, -1}, {0, 0}, {0, 0}, {-1, 1}}
+	for i, w := range want {
+		in.Update()
+		if x, y := menuFlick(in); x != w[0] || y != w[1] {
+			t.Errorf("tick %d: menuFlick = %d, %d, want %d, %d", i, x, y, w[0], w[1])
+		}
+	}
+}
+
+// stubScene is a route to land on
+type stubScene struct{}
+
+func (stubScene) Update() error             { return nil }
+func (stubScene) Draw(screen *ebiten.Image) {}
+
+func TestCardSceneAdvance(t *testing.T) {
+	tests := []struct {
+		name    string
+		frames  []InputFrame
+		advance int // tick the card moves on, -1 for never
+		touched bool
+	}{
+		{
+			name:    "confirm",
+			frames:  []InputFrame{{}, {}, {}, {Held: []Action{ActionConfirm}}},
+			advance: 3,
+		},
+		{
+			// Confirm held from the last scene doesn't skip the card, it has to go down again
+			name:    "held through MinTicks",
+			frames:  []InputFrame{{Held: []Action{ActionConfirm}}, {Held: []Action{ActionConfirm}}, {Held: []Action{ActionConfirm}}, {Held: []Action{ActionConfirm}}},
+			advance: -1,
+		},
+		{
+			name:    "tap",
+			frames:  []InputFrame{{}, {}, {Taps: []Tap{{X: 10, Y: 10, Touch: true}}}},
+			advance: 2,
+			touched: true,
+		},
+		{
+			name:    "too early",
+			frames:  []InputFrame{{Taps: []Tap{{X: 10, Y: 10}}}, {Held: []Action{ActionConfirm}}},
+			advance: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Game{input: &ScriptedInput{Frames: tt.frames}}
+			g.router = NewRouter(g, []Edge{{From: "card", On: EventNext, To: "next"}})
+
+			advanced, touched := -1, false
+			tick := 0
+			g.router.Register("card", func(g *Game, _ any) Scene {
+				return NewCardScene(g, Card{
+					MinTicks:  2,
+					Args:      "manifest",
+					OnAdvance: func(t bool) { advanced, touched = tick, t },
+				})
+			})
+			var args any
+			g.router.Register("next", func(_ *Game, a any) Scene {
+				args = a
+				return stubScene{}
+			})
+			g.router.Go("card", nil, false)
+
+			for ; tick < len(tt.frames)+2; tick++ {
+				if err := g.Update(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if advanced != tt.advance || touched != tt.touched {
+				t.Errorf("advanced on tick %d (touched %v), want %d (%v)", advanced, touched, tt.advance, tt.touched)
+			}
+			wantRoute := "card"
+			if tt.advance >= 0 {
+				wantRoute = "next"
+			}
+			if g.router.Route() != wantRoute {
+				t.Errorf("route = %q, want %q", g.router.Route(), wantRoute)
+			}
+			if tt.advance >= 0 && args != "manifest" {
+				t.Errorf("next got args %v, want the card's", args)
+			}
+		})
+	}
+}
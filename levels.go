@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/ngolebiewski/alley_cat_1999/tiled"
+)
+
+// Level is one stage of the campaign. Everything that used to be hard-coded for NYC
+// lives here, so a new city (or decade!) is a new map in art/tiled, an embed line
+// and an entry in campaign. Where the race starts comes from the map itself:
+// PLAYER_START, RIVAL_START and an optional FINISH on its "Spawns" layer.
+type Level struct {
+	ID      string // save key, never rename once shipped
+	Name    string // shown on the stage select
+	Map     string // .tmx (or .tmj) path in the embedded FS
+	Tileset string // sprite sheet for taxis, smoke and the manifest icon
+	Scale   int    // map pixels to world pixels
+
+	Music       string  // a retrotrack.Songs name
+	TaxiDensity float64 // share of the map's taxi spawns that get a taxi, 0..1
+	Debug       bool    // dev map, only listed (and playable) in debug mode
+
+	// Title card before the manifest
+	TitleImage string
+	TitleText  string
+
+	// Collision grid, streets and pathfinder, built once, see Navigation
+	collide *tiled.CollisionGrid
+	roads   *tiled.RoadGraph
+	paths   *tiled.Pathfinder
+}
+
+// campaign is every stage in the order they unlock
+var campaign = []*Level{
+	{
+		ID:          "nyc_1999",
+		Name:        "NYC 1999",
+		Map:         "art/tiled/nyc_1.tmx", // straight from the Tiled project, no export needed
+		Tileset:     "assets/NEW_nyc_spritesheet-Recovered.png",
+		Scale:       2,
+		Music:       "nyc",
+		TaxiDensity: 1,
+		TitleImage:  "art/aseprite_files/nyc_title.png",
+		TitleText:   "STAGE 1: Bike Messenger Race in NYC.\nControls: Arrows/touch move.\n(B) dismount bike and walk. (Space)/(A): change dir\n(Enter): Pause and see Manifest. (F): Full Screen",
+	},
+	{
+		ID:          "nyc_1999_block",
+		Name:        "THE BLOCK SPRINT",
+		Map:         "art/tiled/nyc_1_TEST.tmx", // the dev test block, hidden until it's a real map
+		Tileset:     "assets/NEW_nyc_spritesheet-Recovered.png",
+		Scale:       2,
+		Music:       "sprint",
+		TaxiDensity: 1,
+		Debug:       true,
+		TitleImage:  "art/aseprite_files/nyc_title.png",
+		TitleText:   "STAGE 2: One block, three stops, all the taxis.\nControls: Arrows/touch move.\n(B) dismount bike and walk. (Space)/(A): change dir\n(Enter): Pause and see Manifest. (F): Full Screen",
+	},
+}
+
+// stages is what the stage select lists: the campaign, minus the dev maps unless
+// debug mode is on. Stage numbers stay the campaign's so manifest codes don't shift.
+func stages() []*Level {
+	var out []*Level
+	for _, l := range campaign {
+		if !l.Debug || isDebugMode {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// levelByID finds a stage, nil if there's no such thing
+func levelByID(id string) *Level {
+	for _, l := range campaign {
+		if l.ID == id {
+			return l
+		}
+	}
+	return nil
+}
+
+// Stage is the level's 1-based place in the campaign
+func (l *Level) Stage() int {
+	for i, c := range campaign {
+		if c == l {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// LoadMap reads the level's map from the embedded FS
+func (l *Level) LoadMap() (*tiled.Map, error) {
+	m, err := tiled.LoadMapFS(embeddedAssets, l.Map)
+	if err != nil {
+		return nil, fmt.Errorf("level %s: %w", l.ID, err)
+	}
+	return m, nil
+}
+
+// Navigation is the level's collision grid, road graph and pathfinder. They're built
+// from m the first time (road extraction and all), then dealing manifests and every
+// race on the level share them, path cache included.
+func (l *Level) Navigation(m *tiled.Map) (*tiled.CollisionGrid, *tiled.RoadGraph, *tiled.Pathfinder) {
+	if l.collide == nil {
+		l.collide, l.roads, l.paths = buildNavigation(m, float64(l.Scale))
+	}
+	return l.collide, l.roads, l.paths
+}
+
+// LoadTileset reads the level's sprite sheet
+func (l *Level) LoadTileset() (*ebiten.Image, error) {
+	img, err := loadImage(l.Tileset)
+	if err != nil {
+		return nil, fmt.Errorf("level %s: %w", l.ID, err)
+	}
+	return img, nil
+}
+
+// Spawns reads the race positions off the level's map (in map pixels).
+// A map without them can't be raced, so the error says exactly what to add.
+func (l *Level) Spawns(m *tiled.Map) (tiled.RaceSpawns, error) {
+	rs, err := tiled.ExtractRaceSpawns(m)
+	if err != nil {
+		return rs, fmt.Errorf("level %s (%s):\n%w", l.ID, l.Map, err)
+	}
+	return rs, nil
+}
+
+// Unlocked says if a stage can be picked: the first always, the rest once the one
+// before was finished. Dev maps don't count as the one before, and are open in debug mode only.
+func (d *SaveData) Unlocked(l *Level) bool {
+	stage := l.Stage()
+	if stage == 0 {
+		return false
+	}
+	if l.Debug {
+		return isDebugMode
+	}
+	for i := stage - 2; i >= 0; i-- {
+		if !campaign[i].Debug {
+			return d.Cleared[campaign[i].ID]
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ngolebiewski/alley_cat_1999/retrotrack"
+)
+
+func TestCampaign(t *testing.T) {
+	ids := map[string]bool{}
+	for i, l := range campaign {
+		if ids[l.ID] {
+			t.Errorf("stage %d: ID %q used twice, saves would mix them up", i+1, l.ID)
+		}
+		ids[l.ID] = true
+
+		if got := l.Stage(); got != i+1 {
+			t.Errorf("%s: Stage() = %d, want %d", l.ID, got, i+1)
+		}
+		if levelByID(l.ID) != l {
+			t.Errorf("levelByID(%q) didn't find it", l.ID)
+		}
+		if _, ok := retrotrack.Songs[l.Music]; !ok {
+			t.Errorf("%s: no song called %q", l.ID, l.Music)
+		}
+		if _, err := l.LoadMap(); err != nil {
+			t.Error(err)
+		}
+	}
+	if levelByID("atlantis_2077") != nil {
+		t.Error("levelByID found a stage that doesn't exist")
+	}
+	if (&Level{ID: "stray"}).Stage() != 0 {
+		t.Error("a level outside the campaign has a stage number")
+	}
+}
+
+// withCampaign swaps in a made-up campaign for the length of a test
+func withCampaign(t *testing.T, levels ...*Level) {
+	saved := campaign
+	campaign = levels
+	t.Cleanup(func() { campaign = saved })
+}
+
+func TestUnlocked(t *testing.T) {
+	one, two, three := &Level{ID: "one"}, &Level{ID: "two"}, &Level{ID: "three"}
+	withCampaign(t, one, two, three)
+
+	tests := []struct {
+		name    string
+		cleared []string
+		want    [3]bool
+	}{
+		{"new save", nil, [3]bool{true, false, false}},
+		{"first cleared", []string{"one"}, [3]bool{true, true, false}},
+		{"all cleared", []string{"one", "two", "three"}, [3]bool{true, true, true}},
+		{"only the second", []string{"two"}, [3]bool{true, false, true}}, // it's the one right before that counts
+	}
+	for _, tt := range tests {
+		d := &SaveData{Cleared: map[string]bool{}}
+		for _, id := range tt.cleared {
+			d.Cleared[id] = true
+		}
+		got := [3]bool{d.Unlocked(one), d.Unlocked(two), d.Unlocked(three)}
+		if got != tt.want {
+			t.Errorf("%s: unlocked %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if (&SaveData{Cleared: map[string]bool{"one": true}}).Unlocked(&Level{ID: "stray"}) {
+		t.Error("a level outside the campaign is unlocked")
+	}
+}
+
+// withDebugMode sets isDebugMode for the length of a test
+func withDebugMode(t *testing.T, on bool) {
+	saved := isDebugMode
+	isDebugMode = on
+	t.Cleanup(func() { isDebugMode = saved })
+}
+
+func TestDebugStages(t *testing.T) {
+	one, dev, two := &Level{ID: "one"}, &Level{ID: "dev", Debug: true}, &Level{ID: "two"}
+	withCampaign(t, one, dev, two)
+	d := &SaveData{Cleared: map[string]bool{"one": true}}
+
+	withDebugMode(t, false)
+	if got := stages(); !slices.Equal(got, []*Level{one, two}) {
+		t.Errorf("stages() lists %d, want the dev map hidden", len(got))
+	}
+	if d.Unlocked(dev) {
+		t.Error("dev map unlocked outside debug mode")
+	}
+	if !d.Unlocked(two) {
+		t.Error("clearing stage 1 should unlock the stage after the dev map")
+	}
+	if two.Stage() != 3 {
+		t.Errorf("Stage() = %d, manifest codes need the campaign's 3", two.Stage())
+	}
+
+	withDebugMode(t, true)
+	if got := stages(); !slices.Equal(got, []*Level{one, dev, two}) {
+		t.Errorf("stages() lists %d in debug mode, want all 3", len(got))
+	}
+	if !(&SaveData{Cleared: map[string]bool{}}).Unlocked(dev) {
+		t.Error("dev map locked in debug mode")
+	}
+}
+
+// The shipped campaign only has real maps outside debug mode
+func TestCampaignHidesTestMap(t *testing.T) {
+	withDebugMode(t, false)
+	for _, l := range stages() {
+		if strings.Contains(l.Map, "TEST") {
+			t.Errorf("%s: %s is a dev map, mark it Debug", l.ID, l.Map)
+		}
+	}
+}
+
+func TestStageSelectScene(t *testing.T) {
+	one, dev, two := &Level{ID: "one"}, &Level{ID: "dev", Debug: true}, &Level{ID: "two"}
+	withCampaign(t, one, dev, two)
+	withDebugMode(t, false)
+
+	down := InputFrame{SteerY: 1}
+	tests := []struct {
+		name    string
+		cleared []string
+		frames  []InputFrame
+		want    *Level // nil if nothing gets picked
+	}{
+		{"first", nil, []InputFrame{{Held: []Action{ActionConfirm}}}, one},
+		{"locked", nil, []InputFrame{down, {}, {Held: []Action{ActionConfirm}}}, nil},
+		{"past the dev map", []string{"one"}, []InputFrame{down, {}, down, {}, {Held: []Action{ActionConfirm}}}, two},
+		{"tap a row", []string{"one"}, []InputFrame{{Taps: []Tap{{X: 40, Y: stageListY + stageRowStep + 5}}}}, two},
+		{"tap under the list", []string{"one"}, []InputFrame{{Taps: []Tap{{X: 40, Y: stageListY + 2*stageRowStep + 5}}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Game{
+				input:   &ScriptedInput{Frames: tt.frames},
+				storage: memoryStorage{},
+				save:    &SaveData{Cleared: map[string]bool{}},
+				level:   one,
+			}
+			for _, id := range tt.cleared {
+				g.save.Cleared[id] = true
+			}
+			g.router = NewRouter(g, []Edge{{From: "stages", On: EventNext, To: "race"}})
+			g.router.Register("stages", func(g *Game, _ any) Scene { return NewStageSelectScene(g) })
+			var picked *Level
+			g.router.Register("race", func(_ *Game, a any) Scene {
+				picked, _ = a.(*Level)
+				return stubScene{}
+			})
+			g.router.Go("stages", nil, false)
+
+			for range tt.frames {
+				if err := g.Update(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if picked != tt.want {
+				t.Errorf("picked %v, want %v", picked, tt.want)
+			}
+			if tt.want != nil && (g.level != tt.want || g.save.Level != tt.want.ID) {
+				t.Errorf("game on %s, save on %q, want %s", g.level.ID, g.save.Level, tt.want.ID)
+			}
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/ngolebiewski/alley_cat_1999/retrotrack"
 )
 
@@ -23,37 +22,61 @@ var isDebugMode = false
 
 // Starting up a State Machine here, to switch between scenes in the game
 // Title -> Race ( + stack for cutscenes, map, rooms, etc. ) -> End Scene
+// The router (router.go) runs it, the flow itself is in routes.go
 type Scene interface {
 	Update() error
 	Draw(screen *ebiten.Image)
 }
 
 type Game struct {
-	scene  Scene
+	router *Router
+	input  Input   // actions from the keyboard, gamepads and touch (see input.go)
 	assets *Assets // ALL game assets are embeded for WASM builds
+
+	// Best times, banked cash and initials, kept between runs (see save.go)
+	storage Storage
+	save    *SaveData
+
+	// The stage being played (see levels.go)
+	level *Level
 }
 
 func (g *Game) Update() error {
-	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
-		ebiten.SetFullscreen(!ebiten.IsFullscreen())
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
-		isDebugMode = !isDebugMode
-		fmt.Println("Debug Mode: ", isDebugMode)
+	g.input.Update()
+
+	// Global hotkeys, unless F and D are letters someone is typing
+	if !g.router.TakesText() {
+		if g.input.JustPressed(ActionFullscreen) {
+			ebiten.SetFullscreen(!ebiten.IsFullscreen())
+		}
+		if g.input.JustPressed(ActionDebug) {
+			isDebugMode = !isDebugMode
+			fmt.Println("Debug Mode: ", isDebugMode)
+		}
 	}
-	return g.scene.Update()
+	return g.router.Update()
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.scene.Draw(screen)
+	g.router.Draw(screen)
 }
 
 func NewGame() *Game {
 	assets := LoadAssets()
+	storage := newStorage()
 	g := &Game{
-		assets: assets,
+		assets:  assets,
+		input:   NewDeviceInput(),
+		storage: storage,
+		save:    LoadSave(storage),
+		level:   campaign[0],
+	}
+	if l := levelByID(g.save.Level); l != nil && g.save.Unlocked(l) {
+		g.level = l
 	}
-	g.scene = NewTitleScene(g)
+	g.router = NewRouter(g, sceneGraph)
+	registerRoutes(g.router)
+	g.router.Go(RouteTitle, nil, false)
 	return g
 }
 
@@ -31,17 +31,28 @@ type Person struct {
 
 type Manifest struct {
 	Checkpoints []*Checkpoint
+	Payout      int    // cash per delivered stop, from the map's "payout" property
+	Seed        int64  // rebuilds this exact manifest, see manifest_code.go
+	Level       string // Level.ID it was dealt on, the code carries its stage too (seedStage)
+	Difficulty  Difficulty
+	Par         float64 // race clock seconds a good run should take
 }
 
-func NewManifest(m *tiled.Map, peopleSheet *ebiten.Image, scale float64) *Manifest {
+// NewManifest picks the stops for a race from the map's spawns. Every random choice
+// comes from seed (difficulty included, see seedDifficulty), so the same map and seed
+// always give the same manifest. A FINISH spawn is always the finish line.
+// Stops are measured by riding distance with paths, the level's (see Level.Navigation).
+func NewManifest(m *tiled.Map, peopleSheet *ebiten.Image, scale float64, seed int64, spawns tiled.RaceSpawns, paths *tiled.Pathfinder) *Manifest {
 	fmt.Println("DEBUG: NewManifest: Extracting CHECKPOINTS from 'Spawns' layer...")
+	rng := rand.New(rand.NewSource(seed))
 
 	// Call the updated extractor
 	rawSpawns := tiled.ExtractManifestCheckpoints(m)
+	payout := m.Properties.Int("payout", 100)
 
 	if len(rawSpawns) == 0 {
 		fmt.Println("DEBUG ERROR: Still no checkpoints found! Double check layer/object names.")
-		return &Manifest{Checkpoints: []*Checkpoint{}}
+		return &Manifest{Checkpoints: []*Checkpoint{}, Payout: payout, Seed: seed}
 	}
 
 	fmt.Printf("DEBUG: Found %d potential checkpoints in JSON\n", len(rawSpawns))
@@ -49,38 +60,72 @@ func NewManifest(m *tiled.Map, peopleSheet *ebiten.Image, scale float64) *Manife
 	availablePeople := makePeople(peopleSheet)
 	var allPossible []*Checkpoint
 
-	for _, s := range rawSpawns {
-		pImg := availablePeople[rand.Intn(len(availablePeople))]
-		allPossible = append(allPossible, &Checkpoint{
-			Name: s.Location, // Will now correctly be "White Space Invader", "Yagg Grafitti", etc.
-			X:    s.X * scale,
-			Y:    s.Y * scale,
+	newCheckpoint := func(name string, x, y float64) *Checkpoint {
+		pImg := availablePeople[rng.Intn(len(availablePeople))]
+		return &Checkpoint{
+			Name: name,
+			X:    x * scale,
+			Y:    y * scale,
 			Client: &Person{
 				Img:       pImg,
-				X:         s.X * scale,
-				Y:         s.Y * scale,
-				StartX:    s.X * scale,
+				X:         x * scale,
+				Y:         y * scale,
+				StartX:    x * scale,
 				Direction: 1,
 				PaceDist:  50.0,
 			},
-		})
+		}
+	}
+	for _, s := range rawSpawns {
+		// Name will now correctly be "White Space Invader", "Yagg Grafitti", etc.
+		allPossible = append(allPossible, newCheckpoint(s.Location, s.X, s.Y))
+	}
+	var finish *Checkpoint
+	if f := spawns.Finish; f != nil {
+		name := f.Location
+		if name == "" {
+			name = "Finish Line"
+		}
+		finish = newCheckpoint(name, f.X, f.Y)
 	}
 
-	// Shuffle and pick mission length (prevents panic)
-	rand.Shuffle(len(allPossible), func(i, j int) {
-		allPossible[i], allPossible[j] = allPossible[j], allPossible[i]
+	// Pick the stops and finish for the difficulty (see manifest_gen.go).
+	// The map can cap the number of stops (finish line included).
+	difficulty := seedDifficulty(seed)
+	start := spawns.PlayerStart
+	ride := func(ax, ay, bx, by float64) float64 { return pathLength(paths, ax, ay, bx, by) }
+	activeCPs, par := planManifest(ride, allPossible, finish, difficulty, start.X*scale, start.Y*scale, m.Properties.Int("checkpoints", 0), rng)
+
+	// The manifest lists stops in any order, don't give away the planned route
+	stops := activeCPs[:len(activeCPs)-1]
+	rng.Shuffle(len(stops), func(i, j int) {
+		stops[i], stops[j] = stops[j], stops[i]
 	})
 
-	numToKeep := len(allPossible)
-	if len(allPossible) > 2 {
-		numToKeep = rand.Intn(len(allPossible)-1) + 2
-	}
+	return &Manifest{Checkpoints: activeCPs, Payout: payout, Seed: seed, Difficulty: difficulty, Par: par}
+}
 
-	activeCPs := allPossible[:numToKeep]
-	activeCPs[len(activeCPs)-1].IsFinishLine = true
+// Code is the shareable code for this manifest
+func (m *Manifest) Code() string {
+	return ManifestCode(m.Seed)
+}
 
-	return &Manifest{Checkpoints: activeCPs}
+// Rand is a fresh generator from the manifest's seed, so every race on the same
+// manifest (retries after the hospital too) lines up the same rivals
+func (m *Manifest) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(m.Seed))
 }
+
+// FinishLine returns the last stop of the manifest, nil if there isn't one
+func (m *Manifest) FinishLine() *Checkpoint {
+	for _, cp := range m.Checkpoints {
+		if cp.IsFinishLine {
+			return cp
+		}
+	}
+	return nil
+}
+
 func (p *Person) Update() {
 	p.BobTimer += 0.05
 	if p.PauseTimer > 0 {
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// A manifest is rebuilt exactly from its seed (same map, same stops, same finish,
+// same clients), so friends can race the same one. The seed is shown as a short
+// code like "1QK-M2D": Crockford base32, no I/L/O/U to misread over the phone.
+// The first character is the stage and the difficulty (stage 1 easy/medium/hard is
+// 0/1/2, stage 2 is 4/5/6 and so on), the rest is random.
+
+const (
+	codeAlphabet    = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	manifestCodeLen = 6 // 30 bits of seed
+)
+
+// The code's first character: 2 bits of difficulty under 3 bits of stage
+const (
+	difficultyShift = 5 * (manifestCodeLen - 1)
+	stageShift      = difficultyShift + 2
+	maxCodeStages   = 8
+)
+
+// NewManifestSeed picks a random seed for a stage (1-based, see Level.Stage) and
+// a difficulty that fits in a manifest code
+func NewManifestSeed(stage int, d Difficulty) int64 {
+	s := int64(max(stage-1, 0) % maxCodeStages)
+	return s<<stageShift | int64(d)<<difficultyShift | rand.Int63n(1<<difficultyShift)
+}
+
+// seedDifficulty reads the difficulty back out of a seed, anything past hard is hard
+func seedDifficulty(seed int64) Difficulty {
+	return min(Difficulty(seed>>difficultyShift&3), DifficultyHard)
+}
+
+// seedStage reads the stage (1-based) back out of a seed
+func seedStage(seed int64) int {
+	return int(seed>>stageShift) + 1
+}
+
+// ManifestCode is the printable form of a seed
+func ManifestCode(seed int64) string {
+	var b [manifestCodeLen]byte
+	for i := manifestCodeLen - 1; i >= 0; i-- {
+		b[i] = codeAlphabet[seed&31]
+		seed >>= 5
+	}
+	return string(b[:3]) + "-" + string(b[3:])
+}
+
+// ParseManifestCode reads a code back into its seed. It's forgiving: any case,
+// dashes and spaces are skipped, and O/I/L are read as 0/1/1.
+func ParseManifestCode(code string) (int64, error) {
+	var seed int64
+	n := 0
+	for _, r := range strings.ToUpper(code) {
+		switch r {
+		case '-', ' ', '\t', '\n', '\r':
+			continue
+		case 'O':
+			r = '0'
+		case 'I', 'L':
+			r = '1'
+		}
+		i := strings.IndexRune(codeAlphabet, r)
+		if i < 0 {
+			return 0, fmt.Errorf("manifest code %q: bad character %q", code, r)
+		}
+		seed = seed<<5 | int64(i)
+		n++
+	}
+	if n != manifestCodeLen {
+		return 0, fmt.Errorf("manifest code %q: want %d characters, got %d", code, manifestCodeLen, n)
+	}
+	return seed, nil
+}
+
+// launchCodeUsed makes the launch code (URL ?manifest=...) only count for the first race,
+// "restart" after that gets a fresh manifest
+var launchCodeUsed = false
+
+// takeLaunchManifestCode returns the code the game was launched with, once
+func takeLaunchManifestCode() string {
+	if launchCodeUsed {
+		return ""
+	}
+	launchCodeUsed = true
+	return launchManifestCode()
+}
@@ -0,0 +1,28 @@
+//go:build !js && !wasm
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// launchManifestCode reads -manifest=XXX-XXX (or -manifest XXX-XXX) from the command line
+func launchManifestCode() string {
+	args := os.Args[1:]
+	for i, a := range args {
+		a = strings.TrimLeft(a, "-")
+		if code, ok := strings.CutPrefix(a, "manifest="); ok {
+			return code
+		}
+		if a == "manifest" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// takePastedText is always empty on desktop, there's no clipboard access, type the code instead
+func takePastedText() string {
+	return ""
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestManifestCodeRoundTrip(t *testing.T) {
+	for stage := 1; stage <= maxCodeStages; stage++ {
+		for d := DifficultyEasy; d <= DifficultyHard; d++ {
+			seed := NewManifestSeed(stage, d)
+			code := ManifestCode(seed)
+			got, err := ParseManifestCode(code)
+			if err != nil {
+				t.Fatalf("stage %d %s: %v", stage, d, err)
+			}
+			if got != seed || seedStage(got) != stage || seedDifficulty(got) != d {
+				t.Errorf("stage %d %s: %s read back as seed %d, stage %d, %s", stage, d, code, got, seedStage(got), seedDifficulty(got))
+			}
+		}
+	}
+}
+
+func TestManifestCodeStage(t *testing.T) {
+	tests := []struct {
+		code  string
+		stage int
+		d     Difficulty
+	}{
+		{"000-000", 1, DifficultyEasy},
+		{"2ZZ-ZZZ", 1, DifficultyHard}, // codes from before stages were in them are stage 1
+		{"5AB-CDE", 2, DifficultyMedium},
+		{"7AB-CDE", 2, DifficultyHard}, // difficulty 3 is hard too
+		{"zab-cde", 8, DifficultyHard},
+	}
+	for _, tt := range tests {
+		seed, err := ParseManifestCode(tt.code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seedStage(seed) != tt.stage || seedDifficulty(seed) != tt.d {
+			t.Errorf("%s: stage %d %s, want stage %d %s", tt.code, seedStage(seed), seedDifficulty(seed), tt.stage, tt.d)
+		}
+	}
+}
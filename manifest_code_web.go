@@ -0,0 +1,41 @@
+//go:build js || wasm
+
+package main
+
+import "syscall/js"
+
+// pasted is the last text pasted into the page, until the scene takes it
+var pasted string
+
+func init() {
+	// Ebitengine has no clipboard, so listen for the browser's paste event
+	// (Ctrl+V / Cmd+V / long-press paste) ourselves
+	js.Global().Get("document").Call("addEventListener", "paste", js.FuncOf(func(this js.Value, args []js.Value) any {
+		data := args[0].Get("clipboardData")
+		if data.Truthy() {
+			pasted = data.Call("getData", "text").String()
+		}
+		return nil
+	}))
+}
+
+// launchManifestCode reads ?manifest=XXX-XXX from the page URL, so a shared link opens the same race
+func launchManifestCode() string {
+	search := js.Global().Get("location").Get("search")
+	if !search.Truthy() {
+		return ""
+	}
+	params := js.Global().Get("URLSearchParams").New(search)
+	code := params.Call("get", "manifest")
+	if code.IsNull() || code.IsUndefined() {
+		return ""
+	}
+	return code.String()
+}
+
+// takePastedText returns what was pasted since the last call, "" if nothing
+func takePastedText() string {
+	text := pasted
+	pasted = ""
+	return text
+}
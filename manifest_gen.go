@@ -0,0 +1,154 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Difficulty shapes how a manifest is put together
+type Difficulty int
+
+const (
+	DifficultyEasy   Difficulty = iota // a couple of stops, more or less on the way to a finish mid-map
+	DifficultyMedium                   // a few stops, some off the straight line, finish well away from the start
+	DifficultyHard                     // lots of stops spread all over the map, finish as far as it gets
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case DifficultyEasy:
+		return "EASY"
+	case DifficultyMedium:
+		return "MEDIUM"
+	default:
+		return "HARD"
+	}
+}
+
+type difficultySpec struct {
+	minStops, maxStops int     // not counting the finish line
+	finishRank         float64 // where the finish sits among the checkpoints by ride distance from the start, 0 closest, 1 farthest
+	spread             float64 // 0 = stops on the way to the finish, 1 = stops as far apart as possible
+}
+
+var difficultySpecs = map[Difficulty]difficultySpec{
+	DifficultyEasy:   {minStops: 2, maxStops: 2, finishRank: 0.5, spread: 0},
+	DifficultyMedium: {minStops: 3, maxStops: 4, finishRank: 0.75, spread: 0.5},
+	DifficultyHard:   {minStops: 5, maxStops: 6, finishRank: 1, spread: 1},
+}
+
+// Par time: the shortest route at cruising speed, plus some slack for traffic and
+// a moment at every stop. Cruising is the player's top speed on the bike
+// (accel 0.2, friction 0.92 → 2.3 px a tick).
+const (
+	parSpeed       = 2.3 * raceTPS // world pixels a second
+	parSlack       = 1.25
+	parStopSeconds = 2.0
+)
+
+// planManifest picks the stops and the finish for a difficulty out of every possible
+// checkpoint, using ride distances (ride, in world pixels) from the start, and works
+// out a par time.
+// fixedFinish (the map's FINISH spawn) is used as is when it isn't nil.
+// maxTotal caps the number of checkpoints (finish included), 0 means no cap.
+// The returned checkpoints end with the finish line.
+func planManifest(ride func(ax, ay, bx, by float64) float64, all []*Checkpoint, fixedFinish *Checkpoint, d Difficulty, startX, startY float64, maxTotal int, rng *rand.Rand) ([]*Checkpoint, float64) {
+	if fixedFinish != nil {
+		all = append(all[:len(all):len(all)], fixedFinish)
+	}
+	if len(all) == 0 {
+		return nil, 0
+	}
+	spec := difficultySpecs[min(max(d, DifficultyEasy), DifficultyHard)]
+
+	// Ride distances between everything: 0 is the start, i+1 is all[i]
+	xs, ys := []float64{startX}, []float64{startY}
+	for _, cp := range all {
+		xs, ys = append(xs, cp.X), append(ys, cp.Y)
+	}
+	dist := make([][]float64, len(xs))
+	for i := range dist {
+		dist[i] = make([]float64, len(xs))
+	}
+	for i := range xs {
+		for j := i + 1; j < len(xs); j++ {
+			dd := ride(xs[i], ys[i], xs[j], ys[j])
+			dist[i][j], dist[j][i] = dd, dd
+		}
+	}
+
+	// The finish: by distance from the start, nudged a place either way so the
+	// same difficulty doesn't always end in the same spot
+	byDistance := make([]int, len(all))
+	for i := range byDistance {
+		byDistance[i] = i + 1
+	}
+	sort.Slice(byDistance, func(a, b int) bool { return dist[0][byDistance[a]] < dist[0][byDistance[b]] })
+	rank := int(math.Round(spec.finishRank*float64(len(all)-1))) + rng.Intn(3) - 1
+	finish := byDistance[min(max(rank, 0), len(all)-1)]
+	if fixedFinish != nil {
+		finish = len(all)
+	}
+
+	// How many stops
+	stops := spec.minStops + rng.Intn(spec.maxStops-spec.minStops+1)
+	if maxTotal > 0 {
+		stops = min(stops, maxTotal-1)
+	}
+	stops = max(min(stops, len(all)-1), 0)
+
+	// The stops, one at a time: spread scores distance from everything picked so far,
+	// the rest scores how little of a detour it is between start and finish.
+	// A bit of noise keeps seeds of the same difficulty from all looking alike.
+	picked := []int{0, finish}
+	used := map[int]bool{finish: true}
+	var chosen []int
+	jitter := 0.25 * dist[0][finish]
+	for len(chosen) < stops {
+		best, bestScore := -1, math.Inf(-1)
+		for c := 1; c < len(xs); c++ {
+			if used[c] {
+				continue
+			}
+			nearest := math.Inf(1)
+			for _, p := range picked {
+				nearest = math.Min(nearest, dist[c][p])
+			}
+			detour := dist[0][c] + dist[c][finish] - dist[0][finish]
+			score := spec.spread*nearest - (1-spec.spread)*detour + rng.Float64()*jitter
+			if score > bestScore {
+				best, bestScore = c, score
+			}
+		}
+		used[best] = true
+		picked = append(picked, best)
+		chosen = append(chosen, best)
+	}
+
+	// Par: the best order through the stops, same planner the optimizer rivals use
+	sub := append(append([]int{0}, chosen...), finish)
+	subDist := make([][]float64, len(sub))
+	for i, a := range sub {
+		subDist[i] = make([]float64, len(sub))
+		for j, b := range sub {
+			subDist[i][j] = dist[a][b]
+		}
+	}
+	order := twoOpt(nearestNeighbour(subDist, len(chosen)), subDist)
+	length, prev := 0.0, 0
+	for _, s := range order {
+		length += subDist[prev][s+1]
+		prev = s + 1
+	}
+	length += subDist[prev][len(sub)-1]
+	par := length/parSpeed*parSlack + float64(len(chosen)+1)*parStopSeconds
+
+	var active []*Checkpoint
+	for _, c := range chosen {
+		active = append(active, all[c-1])
+	}
+	active = append(active, all[finish-1])
+	active[len(active)-1].IsFinishLine = true
+	return active, par
+}
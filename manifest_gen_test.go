@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// straightLine stands in for the level's pathfinder: an empty city, ride as the crow flies
+func straightLine(ax, ay, bx, by float64) float64 {
+	return math.Hypot(bx-ax, by-ay)
+}
+
+// spots makes n checkpoints along the top of the map, a tile apart
+func spots(n int) []*Checkpoint {
+	var cps []*Checkpoint
+	for i := range n {
+		cps = append(cps, &Checkpoint{Name: fmt.Sprintf("CP%d", i), X: float64(24 + i*16), Y: 24})
+	}
+	return cps
+}
+
+func TestPlanManifest(t *testing.T) {
+	tests := []struct {
+		name      string
+		all       int
+		fixed     bool
+		d         Difficulty
+		maxTotal  int
+		wantTotal int // checkpoints returned, finish included
+	}{
+		{"nothing to pick", 0, false, DifficultyEasy, 0, 0},
+		{"just a finish", 1, false, DifficultyHard, 0, 1},
+		{"only the fixed finish", 0, true, DifficultyHard, 0, 1},
+		{"easy", 8, false, DifficultyEasy, 0, 3},
+		{"easy, fixed finish", 8, true, DifficultyEasy, 0, 3},
+		{"hard, fewer checkpoints than stops", 3, false, DifficultyHard, 0, 3},
+		{"hard, fewer checkpoints than stops, fixed finish", 3, true, DifficultyHard, 0, 4},
+		{"capped", 10, false, DifficultyHard, 3, 3},
+		{"capped, fixed finish", 10, true, DifficultyHard, 2, 2},
+		{"cap of one is just the finish", 10, true, DifficultyMedium, 1, 1},
+		{"difficulty past hard", 10, false, Difficulty(9), 0, -1}, // 6 or 7, see below
+	}
+	for _, tt := range tests {
+		for seed := int64(0); seed < 20; seed++ {
+			all := spots(tt.all)
+			var fixed *Checkpoint
+			if tt.fixed {
+				fixed = &Checkpoint{Name: "FINISH", X: 300, Y: 300}
+			}
+			got, par := planManifest(straightLine, all, fixed, tt.d, 8, 8, tt.maxTotal, rand.New(rand.NewSource(seed)))
+
+			want := tt.wantTotal
+			if want < 0 {
+				want = len(got)
+				if want < 6 || want > 7 {
+					t.Errorf("%s, seed %d: got %d checkpoints, want hard's 6 or 7", tt.name, seed, want)
+				}
+			}
+			if len(got) != want {
+				t.Errorf("%s, seed %d: got %d checkpoints, want %d", tt.name, seed, len(got), want)
+				continue
+			}
+			if want == 0 {
+				if par != 0 {
+					t.Errorf("%s: par %v for an empty manifest", tt.name, par)
+				}
+				continue
+			}
+
+			seen := map[*Checkpoint]bool{}
+			for i, cp := range got {
+				if seen[cp] {
+					t.Errorf("%s, seed %d: %s picked twice", tt.name, seed, cp.Name)
+				}
+				seen[cp] = true
+				if last := i == len(got)-1; cp.IsFinishLine != last {
+					t.Errorf("%s, seed %d: %s IsFinishLine = %v", tt.name, seed, cp.Name, cp.IsFinishLine)
+				}
+			}
+			if fixed != nil && got[len(got)-1] != fixed {
+				t.Errorf("%s, seed %d: finish is %s, want the fixed FINISH", tt.name, seed, got[len(got)-1].Name)
+			}
+			if minPar := float64(len(got)) * parStopSeconds; par < minPar {
+				t.Errorf("%s, seed %d: par %v, less than the stops alone (%v)", tt.name, seed, par, minPar)
+			}
+		}
+	}
+}
+
+func TestPlanManifestSeeded(t *testing.T) {
+	plan := func() []string {
+		got, _ := planManifest(straightLine, spots(10), nil, DifficultyMedium, 8, 8, 0, rand.New(rand.NewSource(42)))
+		var names []string
+		for _, cp := range got {
+			names = append(names, cp.Name)
+		}
+		return names
+	}
+	a, b := plan(), plan()
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("same seed, different manifests: %v and %v", a, b)
+	}
+}
+
+func TestPlanManifestPar(t *testing.T) {
+	// One stop straight on the way to the finish: the par is the 192px ride plus slack,
+	// and a moment at both stops
+	all := []*Checkpoint{{Name: "A", X: 104, Y: 8}, {Name: "B", X: 200, Y: 8}}
+	got, par := planManifest(straightLine, all[:1], all[1], DifficultyEasy, 8, 8, 0, rand.New(rand.NewSource(1)))
+	if len(got) != 2 || got[0].Name != "A" || got[1].Name != "B" {
+		t.Fatalf("got %v", got)
+	}
+	want := 192/parSpeed*parSlack + 2*parStopSeconds
+	if diff := par - want; diff < -0.01 || diff > 0.01 {
+		t.Errorf("par = %v, want %v", par, want)
+	}
+}
@@ -1,13 +1,11 @@
 package main
 
 import (
-	"image"
 	"image/color"
 	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
@@ -20,40 +18,42 @@ type joystick struct {
 	currX, currY float64
 }
 
-func (s *RaceScene) updateJoystick() {
+// update follows the touch steering it, or grabs a new one on the left half of the screen
+func (j *joystick) update() {
 	touches := ebiten.TouchIDs()
-	if !s.stick.active {
+	if !j.active {
 		for _, id := range touches {
 			x, y := ebiten.TouchPosition(id)
 			if x < 160*zoom {
-				s.stick.active = true
-				s.stick.id = id
-				s.stick.baseX, s.stick.baseY = float64(x), float64(y)
-				s.stick.currX, s.stick.currY = float64(x), float64(y)
+				j.active = true
+				j.id = id
+				j.baseX, j.baseY = float64(x), float64(y)
+				j.currX, j.currY = float64(x), float64(y)
 				break
 			}
 		}
 	} else {
 		found := false
 		for _, id := range touches {
-			if id == s.stick.id {
+			if id == j.id {
 				x, y := ebiten.TouchPosition(id)
-				s.stick.currX, s.stick.currY = float64(x), float64(y)
+				j.currX, j.currY = float64(x), float64(y)
 				found = true
 				break
 			}
 		}
 		if !found {
-			s.stick.active = false
+			j.active = false
 		}
 	}
 }
 
-func (s *RaceScene) getJoystickVector() (float64, float64) {
-	if !s.stick.active {
+// vector is how far the stick is pushed, -1..1 each way
+func (j *joystick) vector() (float64, float64) {
+	if !j.active {
 		return 0, 0
 	}
-	dx, dy := s.stick.currX-s.stick.baseX, s.stick.currY-s.stick.baseY
+	dx, dy := j.currX-j.baseX, j.currY-j.baseY
 	dist := math.Sqrt(dx*dx + dy*dy)
 	if dist < 4 {
 		return 0, 0
@@ -61,53 +61,11 @@ func (s *RaceScene) getJoystickVector() (float64, float64) {
 	return dx / dist, dy / dist
 }
 
-// Update the touch-detection to recognize the B button rectangle
-func (s *RaceScene) isButtonPressed(label string) bool {
-	touches := ebiten.TouchIDs()
-	var r image.Rectangle
-	switch label {
-	case "A":
-		r = image.Rect(270*zoom, 170*zoom, 310*zoom, 210*zoom)
-	case "B":
-		r = image.Rect(220*zoom, 170*zoom, 260*zoom, 210*zoom) // Fixed X coordinates
-	case "PAUSE":
-		r = image.Rect(130*zoom, 210*zoom, 190*zoom, 235*zoom)
-	}
-	for _, id := range touches {
-		x, y := ebiten.TouchPosition(id)
-		if image.Pt(x, y).In(r) {
-			return true
-		}
-	}
-	return false
-}
-
-func (s *RaceScene) isButtonJustPressed(label string) bool {
-	touches := inpututil.AppendJustPressedTouchIDs(nil)
-	var r image.Rectangle
-
-	switch label {
-	case "A":
-		// Centered at 290, radius 20
-		r = image.Rect(270*zoom, 170*zoom, 310*zoom, 210*zoom)
-	case "B":
-		// Centered at 240, radius 20
-		r = image.Rect(220*zoom, 170*zoom, 260*zoom, 210*zoom)
-	}
-
-	for _, id := range touches {
-		x, y := ebiten.TouchPosition(id)
-		if image.Pt(x, y).In(r) {
-			return true
-		}
-	}
-	return false
-}
 func (s *RaceScene) drawMobileUI(screen *ebiten.Image) {
 	// Virtual Joystick
-	if s.stick.active {
-		vector.FillCircle(screen, float32(s.stick.baseX), float32(s.stick.baseY), 20, color.RGBA{255, 255, 255, 40}, true)
-		vector.FillCircle(screen, float32(s.stick.currX), float32(s.stick.currY), 10, color.RGBA{255, 255, 255, 120}, true)
+	if stick := s.game.input.Joystick(); stick != nil && stick.active {
+		vector.FillCircle(screen, float32(stick.baseX), float32(stick.baseY), 20, color.RGBA{255, 255, 255, 40}, true)
+		vector.FillCircle(screen, float32(stick.currX), float32(stick.currY), 10, color.RGBA{255, 255, 255, 120}, true)
 	}
 
 	// B Button (Brake/Skid) - Positioned at (240, 190)
@@ -5,7 +5,6 @@ import (
 	"image/color"
 	"math"
 	"math/rand"
-	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
@@ -21,14 +20,25 @@ type NPCBiker struct {
 	speed      float64
 	color      ebiten.ColorScale
 
+	// Riding style, from RivalConfig
+	Personality Personality
+	Risk        float64
+
 	// Progress
 	Inventory     map[string]bool
 	Finished      bool
 	FinalTime     float64
 	CurrentTarget *Checkpoint
 
-	// Route Logic: Unique order of checkpoints for this specific NPC
+	// Route Logic: Unique order of checkpoints for this specific NPC, planned by personality
 	RouteOrder []string
+	planned    bool
+	rng        *rand.Rand // the manifest's, see Manifest.Rand
+
+	// Path to CurrentTarget from the scene's pathfinder, in world pixels (cell centers)
+	path       []tiled.Point
+	pathStep   int
+	pathTarget *Checkpoint
 
 	// AI Logic
 	StuckTimer   int
@@ -48,52 +58,29 @@ type NPCManager struct {
 	Bikers []*NPCBiker
 }
 
-func NewNPCManager(startX, startY float64, scene *RaceScene) *NPCManager {
+func NewNPCManager(startX, startY float64, scene *RaceScene, rng *rand.Rand) *NPCManager {
 	manager := &NPCManager{}
-	rand.Seed(time.Now().UnixNano())
-
-	rivalConfigs := []struct {
-		name  string
-		color []float32
-	}{
-		{"Purple Haze", []float32{0.8, 0.4, 1.0}},
-		{"Blue Streak", []float32{0.4, 0.4, 1.0}},
-		{"Green Machine", []float32{0.4, 1.0, 0.4}},
-		{"Yellow Jacket", []float32{1.0, 1.0, 0.4}},
-	}
-
-	// Prepare list of checkpoint names (excluding finish line)
-	var cpNames []string
-	for _, cp := range scene.manifest.Checkpoints {
-		if !cp.IsFinishLine {
-			cpNames = append(cpNames, cp.Name)
-		}
-	}
 
-	for i, config := range rivalConfigs {
+	for i, config := range rivalField {
 		cs := ebiten.ColorScale{}
-		cs.Scale(config.color[0], config.color[1], config.color[2], 1.0)
-
-		// Create a unique shuffled route for this specific NPC
-		npcRoute := make([]string, len(cpNames))
-		copy(npcRoute, cpNames)
-		rand.Shuffle(len(npcRoute), func(i, j int) {
-			npcRoute[i], npcRoute[j] = npcRoute[j], npcRoute[i]
-		})
+		cs.Scale(config.Color[0], config.Color[1], config.Color[2], 1.0)
 
+		// RouteOrder is planned on the first update, see planRoute
 		biker := &NPCBiker{
-			Name:            config.name,
+			Name:            config.Name,
 			x:               startX + float64(i*32),
 			y:               startY,
 			w:               18,
 			h:               18,
-			speed:           1.5 + (rand.Float64() * 0.3),
+			speed:           config.Speed,
 			color:           cs,
+			Personality:     config.Personality,
+			Risk:            config.Risk,
 			Inventory:       make(map[string]bool),
-			RouteOrder:      npcRoute,
 			dir:             3,
-			animOffset:      rand.Intn(60),
-			StartDelayTicks: 30 + rand.Intn(90),
+			rng:             rng,
+			animOffset:      rng.Intn(60),
+			StartDelayTicks: 30 + rng.Intn(90),
 		}
 		manager.Bikers = append(manager.Bikers, biker)
 	}
@@ -118,25 +105,16 @@ func (n *NPCBiker) Bounds() image.Rectangle {
 	return image.Rect(int(n.x), int(n.y), int(n.x+n.w), int(n.y+n.h))
 }
 
+// Center is the middle of the sprite in world pixels, like Player.Center
+func (n *NPCBiker) Center() (float64, float64) {
+	return n.x + n.w/2, n.y + n.h/2
+}
+
 func (n *NPCBiker) wouldCollideAt(newX, newY float64, grid *tiled.CollisionGrid) bool {
 	if grid == nil {
 		return false
 	}
-	const tileSize = 32
-	points := [][2]float64{
-		{newX, newY}, {newX + n.w, newY},
-		{newX, newY + n.h}, {newX + n.w, newY + n.h},
-		{newX + n.w/2, newY + n.h/2},
-	}
-	for _, p := range points {
-		ix, iy := int(p[0])/tileSize, int(p[1])/tileSize
-		if iy >= 0 && iy < grid.Height && ix >= 0 && ix < grid.Width {
-			if grid.Solid[iy][ix] {
-				return true
-			}
-		}
-	}
-	return false
+	return grid.RectCollides(newX, newY, n.w, n.h)
 }
 
 // --- AI & Update ---
@@ -150,8 +128,11 @@ func (n *NPCBiker) Update(manifest *Manifest, taxis []*Taxi, scene *RaceScene, g
 		return
 	}
 
-	n.findTarget(manifest)
-	n.applyManhattanAI(scene, grid, taxis)
+	if !n.planned {
+		n.planRoute(manifest, scene)
+	}
+	n.findTarget(manifest, scene)
+	n.followRoute(scene, taxis)
 
 	oldX, oldY := n.x, n.y
 	n.x += n.velX
@@ -170,7 +151,9 @@ func (n *NPCBiker) Update(manifest *Manifest, taxis []*Taxi, scene *RaceScene, g
 		}
 	}
 
-	if math.Abs(n.x-n.LastX)+math.Abs(n.y-n.LastY) < 0.05 {
+	// Only stuck if we meant to move (a drafter on the player's wheel stands still on purpose)
+	wantsToMove := n.velX != 0 || n.velY != 0
+	if wantsToMove && math.Abs(n.x-n.LastX)+math.Abs(n.y-n.LastY) < 0.05 {
 		n.StuckTimer++
 	} else {
 		n.StuckTimer = 0
@@ -181,77 +164,53 @@ func (n *NPCBiker) Update(manifest *Manifest, taxis []*Taxi, scene *RaceScene, g
 	}
 
 	n.updateAnimation()
-	n.checkCheckpoints(totalTime)
+	n.checkCheckpoints(manifest, totalTime)
 }
 
-func (n *NPCBiker) applyManhattanAI(scene *RaceScene, grid *tiled.CollisionGrid, taxis []*Taxi) {
+// followRoute steers along the A* path to the current target, planning a new one
+// when the target changes or the biker got knocked off it
+func (n *NPCBiker) followRoute(scene *RaceScene, taxis []*Taxi) {
 	if n.CurrentTarget == nil {
 		return
 	}
 
-	dx, dy := n.CurrentTarget.X-n.x, n.CurrentTarget.Y-n.y
-	distToTarget := math.Hypot(dx, dy)
-
-	moveX, moveY := 0.0, 0.0
-
-	// 1. Primary Manhattan Direction
-	if math.Abs(dx) > math.Abs(dy) {
-		moveX = n.speed
-		if dx < 0 {
-			moveX = -n.speed
+	cx, cy := n.x+n.w/2, n.y+n.h/2
+	if n.pathTarget != n.CurrentTarget || n.StuckTimer == 60 {
+		n.pathTarget = n.CurrentTarget
+		n.path, n.pathStep = nil, 0
+		if scene.paths != nil {
+			n.path = scene.paths.FindPath(cx, cy, n.CurrentTarget.X, n.CurrentTarget.Y)
 		}
-	} else {
-		moveY = n.speed
-		if dy < 0 {
-			moveY = -n.speed
+	}
+
+	// Next waypoint, skipping the ones we're already on (reckless riders cut corners).
+	// Past the end of the path (or with no path at all) ride straight at the checkpoint.
+	tx, ty := n.CurrentTarget.X, n.CurrentTarget.Y
+	for n.pathStep < len(n.path) {
+		wp := n.path[n.pathStep]
+		if math.Hypot(wp.X-cx, wp.Y-cy) > n.speed*2+n.Risk*16 {
+			tx, ty = wp.X, wp.Y
+			break
 		}
+		n.pathStep++
 	}
 
-	// 2. Road-Staying Logic (Only if not right next to the target)
-	if distToTarget > 64 {
-		currentTile := scene.getTileIDAt(n.x+(n.w/2), n.y+(n.h/2), "Roads and Sidewalks")
-
-		// If we are NOT on a roadway (Tile 2), try to nudge back toward it
-		if currentTile != 2 {
-			// Scan a small area around the NPC to find where the road is
-			foundRoad := false
-			offsets := []float64{-32, 32, -64, 64}
-
-			for _, off := range offsets {
-				// If moving vertically, check horizontal for road
-				if moveY != 0 {
-					if scene.getTileIDAt(n.x+off, n.y, "Roads and Sidewalks") == 2 {
-						moveX = n.speed
-						if off < 0 {
-							moveX = -n.speed
-						}
-						foundRoad = true
-						break
-					}
-				} else { // If moving horizontally, check vertical for road
-					if scene.getTileIDAt(n.x, n.y+off, "Roads and Sidewalks") == 2 {
-						moveY = n.speed
-						if off < 0 {
-							moveY = -n.speed
-						}
-						foundRoad = true
-						break
-					}
-				}
-			}
+	// Drafters sit on the player's wheel while they're close enough to hold it
+	if px, py, ok := n.draftTarget(scene); ok {
+		tx, ty = px, py
+	}
 
-			// If we are really deep in the sidewalk and can't see the road,
-			// just prioritize getting back to the center of the screen/map area
-			if !foundRoad {
-				// fallback: nudge toward middle of typical road widths
-			}
-		}
+	moveX, moveY := 0.0, 0.0
+	if dx, dy := tx-cx, ty-cy; math.Hypot(dx, dy) > 1 {
+		d := math.Hypot(dx, dy)
+		moveX, moveY = dx/d*n.speed, dy/d*n.speed
 	}
 
-	// 3. Taxi Avoidance (Keep your existing logic)
+	// Taxi Avoidance: swerve sideways to the direction of travel, the careful ones early
+	avoid := 60 * (1.5 - n.Risk)
 	for _, t := range taxis {
-		if math.Hypot(n.x-t.x, n.y-t.y) < 60 {
-			if moveX != 0 {
+		if math.Hypot(n.x-t.x, n.y-t.y) < avoid {
+			if math.Abs(moveX) > math.Abs(moveY) {
 				moveY = n.speed
 				if t.y > n.y {
 					moveY = -n.speed
@@ -265,28 +224,51 @@ func (n *NPCBiker) applyManhattanAI(scene *RaceScene, grid *tiled.CollisionGrid,
 		}
 	}
 
-	// 4. Collision Look-ahead (Keep your existing logic)
-	if n.wouldCollideAt(n.x+moveX*15, n.y+moveY*15, grid) {
-		if moveX != 0 {
-			moveX = 0
-			moveY = n.speed
-			if dy < 0 {
-				moveY = -n.speed
+	n.velX, n.velY = moveX, moveY
+}
+
+// draftTarget is the spot behind the player a drafter rides to. ok is false for other
+// personalities, and when the player got away (then the drafter rides their own route).
+func (n *NPCBiker) draftTarget(scene *RaceScene) (float64, float64, bool) {
+	if n.Personality != PersonalityDrafter || scene.player == nil {
+		return 0, 0, false
+	}
+	cx, cy := n.x+n.w/2, n.y+n.h/2
+	px, py := scene.player.Center()
+	d := math.Hypot(px-cx, py-cy)
+	if d > 400 {
+		return 0, 0, false
+	}
+	if d < draftDistance {
+		return cx, cy, true // right on the wheel, just hold
+	}
+	return px - (px-cx)/d*draftDistance, py - (py-cy)/d*draftDistance, true
+}
+
+func (n *NPCBiker) findTarget(manifest *Manifest, scene *RaceScene) {
+	if n.CurrentTarget != nil && !n.Inventory[n.CurrentTarget.Name] {
+		return // still on the way
+	}
+	n.CurrentTarget = nil
+
+	// Greedy riders look around again after every stop
+	if n.Personality == PersonalityGreedy {
+		cx, cy := n.x+n.w/2, n.y+n.h/2
+		best := math.Inf(1)
+		for _, cp := range manifest.Checkpoints {
+			if cp.IsFinishLine || n.Inventory[cp.Name] {
+				continue
 			}
-		} else {
-			moveY = 0
-			moveX = n.speed
-			if dx < 0 {
-				moveX = -n.speed
+			if d := rideDistance(scene, cx, cy, cp.X, cp.Y); d < best {
+				best, n.CurrentTarget = d, cp
 			}
 		}
+		if n.CurrentTarget != nil {
+			return
+		}
 	}
 
-	n.velX, n.velY = moveX, moveY
-}
-
-func (n *NPCBiker) findTarget(manifest *Manifest) {
-	// Look through the shuffled route first
+	// Everyone else follows their planned order
 	for _, name := range n.RouteOrder {
 		if !n.Inventory[name] {
 			// Find the actual checkpoint object by name
@@ -299,23 +281,29 @@ func (n *NPCBiker) findTarget(manifest *Manifest) {
 		}
 	}
 	// If all route checkpoints are done, go to finish line
+	n.CurrentTarget = manifest.FinishLine()
+}
+
+// checkCheckpoints collects any stop the rider is at (drafters pass stops they weren't
+// heading for). The finish line only counts once every other stop is done.
+func (n *NPCBiker) checkCheckpoints(manifest *Manifest, totalTime float64) {
+	allDone := true
 	for _, cp := range manifest.Checkpoints {
-		if cp.IsFinishLine {
-			n.CurrentTarget = cp
-			return
+		if !cp.IsFinishLine && !n.Inventory[cp.Name] {
+			allDone = false
 		}
 	}
-}
 
-func (n *NPCBiker) checkCheckpoints(totalTime float64) {
-	if n.CurrentTarget == nil {
-		return
-	}
-	if math.Hypot(n.x-n.CurrentTarget.X, n.y-n.CurrentTarget.Y) < 48 {
-		n.Inventory[n.CurrentTarget.Name] = true
-		if n.CurrentTarget.IsFinishLine {
-			n.Finished = true
-			n.FinalTime = totalTime
+	for _, cp := range manifest.Checkpoints {
+		if n.Inventory[cp.Name] || (cp.IsFinishLine && !allDone) {
+			continue
+		}
+		if math.Hypot(n.x-cp.X, n.y-cp.Y) < 48 {
+			n.Inventory[cp.Name] = true
+			if cp.IsFinishLine {
+				n.Finished = true
+				n.FinalTime = totalTime
+			}
 		}
 	}
 }
@@ -325,8 +313,9 @@ func (n *NPCBiker) respawnOnRoad(scene *RaceScene, grid *tiled.CollisionGrid) {
 	for radius := 32.0; radius < 400.0; radius += 32.0 {
 		for angle := 0.0; angle < math.Pi*2; angle += math.Pi / 4 {
 			tx, ty := n.x+math.Cos(angle)*radius, n.y+math.Sin(angle)*radius
-			if !n.wouldCollideAt(tx, ty, grid) && scene.getTileIDAt(tx, ty, "Roads and Sidewalks") == 2 {
+			if !n.wouldCollideAt(tx, ty, grid) && scene.getTileIDAt(tx, ty, roadLayerName) == roadGID {
 				n.x, n.y = tx, ty
+				n.pathTarget = nil // plan again from here
 				return
 			}
 		}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"math"
+
+	"github.com/ngolebiewski/alley_cat_1999/tiled"
+)
+
+// Personality decides how a rival plans the order of their stops
+type Personality string
+
+const (
+	PersonalityOptimizer Personality = "optimizer" // plans the whole route: nearest neighbour, then 2-opt
+	PersonalityGreedy    Personality = "greedy"    // always heads for the closest stop left
+	PersonalityChaotic   Personality = "chaotic"   // random order, pure vibes
+	PersonalityDrafter   Personality = "drafter"   // sits on the player's wheel, grabs stops on the way
+)
+
+// RivalConfig is one rider in the alley cat field
+type RivalConfig struct {
+	Name        string
+	Color       [3]float32
+	Personality Personality
+	Speed       float64 // pixels per tick
+	Risk        float64 // 0 = careful, 1 = reckless: how close taxis get before swerving, how hard corners get cut
+}
+
+// rivalField is who lines up against the player
+var rivalField = []RivalConfig{
+	{Name: "Purple Haze", Color: [3]float32{0.8, 0.4, 1.0}, Personality: PersonalityOptimizer, Speed: 1.6, Risk: 0.4},
+	{Name: "Blue Streak", Color: [3]float32{0.4, 0.4, 1.0}, Personality: PersonalityGreedy, Speed: 1.7, Risk: 0.6},
+	{Name: "Green Machine", Color: [3]float32{0.4, 1.0, 0.4}, Personality: PersonalityChaotic, Speed: 1.8, Risk: 0.9},
+	{Name: "Yellow Jacket", Color: [3]float32{1.0, 1.0, 0.4}, Personality: PersonalityDrafter, Speed: 1.5, Risk: 0.2},
+}
+
+// draftDistance is how far behind the player a drafter rides
+const draftDistance = 40.0
+
+// planRoute fills RouteOrder for the rival's personality. Runs once, on the first
+// update after the start delay, so the scene's pathfinder is there to measure with.
+func (n *NPCBiker) planRoute(manifest *Manifest, scene *RaceScene) {
+	n.planned = true
+
+	var stops []*Checkpoint
+	for _, cp := range manifest.Checkpoints {
+		if !cp.IsFinishLine {
+			stops = append(stops, cp)
+		}
+	}
+
+	var order []int
+	switch n.Personality {
+	case PersonalityChaotic:
+		order = n.rng.Perm(len(stops))
+	case PersonalityOptimizer:
+		dist := n.stopDistances(stops, manifest, scene)
+		order = twoOpt(nearestNeighbour(dist, len(stops)), dist)
+	default:
+		// Greedy and drafter start with the nearest-neighbour order, greedy re-picks
+		// after every stop and the drafter mostly ignores it (see findTarget)
+		dist := n.stopDistances(stops, manifest, scene)
+		order = nearestNeighbour(dist, len(stops))
+	}
+
+	n.RouteOrder = n.RouteOrder[:0]
+	for _, i := range order {
+		n.RouteOrder = append(n.RouteOrder, stops[i].Name)
+	}
+}
+
+// stopDistances is the ride length between every pair of points: index 0 is the rival,
+// 1..len(stops) the stops, and the last one the finish line (if the manifest has one).
+func (n *NPCBiker) stopDistances(stops []*Checkpoint, manifest *Manifest, scene *RaceScene) [][]float64 {
+	type pt struct{ x, y float64 }
+	cx, cy := n.Center()
+	points := []pt{{cx, cy}}
+	for _, cp := range stops {
+		points = append(points, pt{cp.X, cp.Y})
+	}
+	if finish := manifest.FinishLine(); finish != nil {
+		points = append(points, pt{finish.X, finish.Y})
+	}
+
+	dist := make([][]float64, len(points))
+	for i := range points {
+		dist[i] = make([]float64, len(points))
+	}
+	for i := range points {
+		for j := i + 1; j < len(points); j++ {
+			d := rideDistance(scene, points[i].x, points[i].y, points[j].x, points[j].y)
+			dist[i][j], dist[j][i] = d, d
+		}
+	}
+	return dist
+}
+
+// rideDistance is the length of the A* route between two points, or the straight
+// line when there's no pathfinder or no route
+func rideDistance(scene *RaceScene, ax, ay, bx, by float64) float64 {
+	if scene == nil {
+		return math.Hypot(bx-ax, by-ay)
+	}
+	return pathLength(scene.paths, ax, ay, bx, by)
+}
+
+// pathLength is rideDistance for any pathfinder (nil is fine)
+func pathLength(paths *tiled.Pathfinder, ax, ay, bx, by float64) float64 {
+	if paths != nil {
+		if path := paths.FindPath(ax, ay, bx, by); len(path) > 0 {
+			total := math.Hypot(path[0].X-ax, path[0].Y-ay)
+			for i := 1; i < len(path); i++ {
+				total += math.Hypot(path[i].X-path[i-1].X, path[i].Y-path[i-1].Y)
+			}
+			return total
+		}
+	}
+	return math.Hypot(bx-ax, by-ay)
+}
+
+// nearestNeighbour builds a stop order from point 0, always going to the closest
+// unvisited stop. Returns stop indexes (0-based, so point i+1 is stop i).
+func nearestNeighbour(dist [][]float64, count int) []int {
+	visited := make([]bool, count)
+	order := make([]int, 0, count)
+	cur := 0
+	for len(order) < count {
+		best := -1
+		for s := 0; s < count; s++ {
+			if !visited[s] && (best < 0 || dist[cur][s+1] < dist[cur][best+1]) {
+				best = s
+			}
+		}
+		visited[best] = true
+		order = append(order, best)
+		cur = best + 1
+	}
+	return order
+}
+
+// twoOpt keeps reversing stretches of the route while that makes it shorter.
+// The start (point 0) and finish (last point, if there's one past the stops) stay put.
+func twoOpt(order []int, dist [][]float64) []int {
+	hasFinish := len(dist) > len(order)+1
+	length := func(o []int) float64 {
+		total, prev := 0.0, 0
+		for _, s := range o {
+			total += dist[prev][s+1]
+			prev = s + 1
+		}
+		if hasFinish {
+			total += dist[prev][len(dist)-1]
+		}
+		return total
+	}
+
+	best := length(order)
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < len(order)-1; i++ {
+			for j := i + 1; j < len(order); j++ {
+				reverse(order[i : j+1])
+				if l := length(order); l < best-0.001 {
+					best, improved = l, true
+				} else {
+					reverse(order[i : j+1])
+				}
+			}
+		}
+	}
+	return order
+}
+
+func reverse(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// lineDistances is a dist matrix for points on a line: [0] is the rival, then the stops
+func lineDistances(xs ...float64) [][]float64 {
+	dist := make([][]float64, len(xs))
+	for i := range xs {
+		dist[i] = make([]float64, len(xs))
+		for j := range xs {
+			dist[i][j] = math.Abs(xs[i] - xs[j])
+		}
+	}
+	return dist
+}
+
+// planeDistances is the same for points on a plane
+func planeDistances(pts ...[2]float64) [][]float64 {
+	dist := make([][]float64, len(pts))
+	for i := range pts {
+		dist[i] = make([]float64, len(pts))
+		for j := range pts {
+			dist[i][j] = math.Hypot(pts[i][0]-pts[j][0], pts[i][1]-pts[j][1])
+		}
+	}
+	return dist
+}
+
+func TestNearestNeighbour(t *testing.T) {
+	tests := []struct {
+		name  string
+		dist  [][]float64
+		count int
+		want  []int
+	}{
+		{"no stops", lineDistances(0), 0, []int{}},
+		{"one stop", lineDistances(0, 5), 1, []int{0}},
+		{"in a row", lineDistances(0, 30, 10, 20), 3, []int{1, 2, 0}},
+		{"both ways", lineDistances(0, -4, 3, 10), 3, []int{1, 0, 2}},
+		{"ties go to the first", lineDistances(0, 5, -5), 2, []int{0, 1}},
+		{"finish is ignored", lineDistances(0, 10, 20, 1), 2, []int{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nearestNeighbour(tt.dist, tt.count); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// routeLength is twoOpt's measure: start, the stops in order, then the finish if there is one
+func routeLength(order []int, dist [][]float64) float64 {
+	total, prev := 0.0, 0
+	for _, s := range order {
+		total += dist[prev][s+1]
+		prev = s + 1
+	}
+	if len(dist) > len(order)+1 {
+		total += dist[prev][len(dist)-1]
+	}
+	return total
+}
+
+func TestTwoOpt(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []int
+		dist  [][]float64
+		want  []int
+	}{
+		{"empty", []int{}, lineDistances(0), []int{}},
+		{"already best", []int{0, 1, 2}, lineDistances(0, 10, 20, 30), []int{0, 1, 2}},
+		{"backwards", []int{2, 1, 0}, lineDistances(0, 10, 20, 30), []int{0, 1, 2}},
+		{"zigzag", []int{1, 0, 2}, lineDistances(0, 10, 20, 30), []int{0, 1, 2}},
+		// Nearest neighbour goes left first, then has to ride all the way back past
+		// the start to the finish on the right
+		{"finish stays last", []int{0, 1}, lineDistances(0, -1, 5, 10), []int{0, 1}},
+		{"finish pulls the route", []int{1, 0}, lineDistances(0, 5, -1, -10), []int{0, 1}},
+		// A square's corners, visited crossing over the middle
+		{"uncrossed", []int{0, 2, 1}, planeDistances([2]float64{0, 0}, [2]float64{0, 10}, [2]float64{10, 10}, [2]float64{10, 0}), []int{0, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := routeLength(tt.order, tt.dist)
+			got := twoOpt(slices.Clone(tt.order), tt.dist)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if after := routeLength(got, tt.dist); after > before {
+				t.Errorf("route got longer: %v -> %v", before, after)
+			}
+		})
+	}
+}
+
+func TestTwoOptBeatsNearestNeighbour(t *testing.T) {
+	// Greedy grabs the close stop on the left, then rides out right and back left
+	dist := lineDistances(0, -2, 3, -8)
+	nn := nearestNeighbour(dist, 3)
+	if want := []int{0, 1, 2}; !slices.Equal(nn, want) {
+		t.Fatalf("nearestNeighbour = %v, want %v", nn, want)
+	}
+	opt := twoOpt(slices.Clone(nn), dist)
+	if routeLength(opt, dist) >= routeLength(nn, dist) {
+		t.Errorf("twoOpt %v (%v) is no shorter than %v (%v)", opt, routeLength(opt, dist), nn, routeLength(nn, dist))
+	}
+}
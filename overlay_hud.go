@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"image/color"
 	"math"
-	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
@@ -12,24 +11,31 @@ import (
 )
 
 type HUDOverlay struct {
-	startTime   time.Time
-	health      float32 // 0.0 to 1.0 (synced from Player.health / 100)
+	clock       *RaceClock // owned by RaceScene
+	health      float32    // 0.0 to 1.0 (synced from Player.health / 100)
 	cash        int
 	checkpoints int
 	maxCheck    int
+
+	// Live place in the field, 0 hides it
+	position int
+	riders   int
+
+	// Last check-in's split, shown until the race clock passes splitUntil
+	splitText  string
+	splitUntil int
 }
 
-func NewHUDOverlay() *HUDOverlay {
+func NewHUDOverlay(clock *RaceClock) *HUDOverlay {
 	return &HUDOverlay{
-		startTime: time.Now(),
-		health:    1.0,
-		maxCheck:  3,
+		clock:    clock,
+		health:   1.0,
+		maxCheck: 3,
 	}
 }
 
 func (h *HUDOverlay) Update(paused bool) {
-	// Logic-wise, we don't need to do anything for time here anymore
-	// since time.Since() handles the heavy lifting in Draw.
+	// Nothing to do for time here, RaceScene ticks the race clock
 }
 
 func (h *HUDOverlay) Draw(screen *ebiten.Image) {
@@ -64,6 +70,15 @@ func (h *HUDOverlay) Draw(screen *ebiten.Image) {
 	cashStr := fmt.Sprintf("$ %d", h.cash)
 	ebitenutil.DebugPrintAt(screen, cashStr, 270*zoom, 5*zoom)
 
+	if h.position > 0 {
+		posStr := fmt.Sprintf("POS %d/%d", h.position, h.riders)
+		ebitenutil.DebugPrintAt(screen, posStr, 5*zoom, 27*zoom)
+	}
+
+	if h.splitText != "" && h.clock.Ticks() < h.splitUntil {
+		ebitenutil.DebugPrintAt(screen, h.splitText, 90*zoom, 27*zoom)
+	}
+
 	// 4. Hospital State Wash
 	if h.health <= 0 {
 		vector.DrawFilledRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()), color.RGBA{255, 255, 255, 100}, false)
@@ -86,9 +101,9 @@ func (h *HUDOverlay) drawBigHeart(screen *ebiten.Image, x, y float32, clr color.
 	vector.DrawFilledRect(screen, x+2*s, y+3*s, s, s, clr, false)
 }
 
-// elapsedTimeStr uses your preferred time.Since logic
+// elapsedTimeStr shows the race clock
 func (h *HUDOverlay) elapsedTimeStr() string {
-	elapsed := time.Since(h.startTime)
+	elapsed := h.clock.Elapsed()
 	h_val := int(elapsed.Hours())
 	m_val := int(elapsed.Minutes()) % 60
 	s_val := int(elapsed.Seconds()) % 60
@@ -97,35 +112,13 @@ func (h *HUDOverlay) elapsedTimeStr() string {
 	return fmt.Sprintf("TIME %02d:%02d:%02d", h_val, m_val, s_val)
 }
 
-func (h *HUDOverlay) Reset() {
-	h.startTime = time.Now()
-	h.health = 1.0
+// showSplit flashes a check-in's split (and delta) under the top bar
+func (h *HUDOverlay) showSplit(text string) {
+	h.splitText = text
+	h.splitUntil = h.clock.Ticks() + splitFlashTicks
 }
 
-func (s *RaceScene) drawPauseOverlay(screen *ebiten.Image) {
-	// 1. Draw a dark semi-transparent overlay
-	vector.FillRect(screen, 0, 0, float32(screenWidth), float32(screenHeight), color.RGBA{0, 0, 0, 180}, false)
-
-	// 2. Draw the list
-	yOff := 50
-	ebitenutil.DebugPrintAt(screen, "--- ALLEY CAT RACE MANIFEST (PAUSED) ---", 40, yOff)
-	yOff += 30
-
-	if s.manifest != nil {
-		for i, cp := range s.manifest.Checkpoints {
-			status := "[ ] "
-			if cp.IsComplete {
-				status = "[x] "
-			}
-
-			line := status + cp.Name
-			if cp.IsFinishLine {
-				line = "[FINISH] " + cp.Name
-			}
-
-			ebitenutil.DebugPrintAt(screen, line, 50, yOff+(i*15))
-		}
-	}
-
-	ebitenutil.DebugPrintAt(screen, "\nPRESS ENTER TO RESUME", 40, screenHeight-40)
+func (h *HUDOverlay) Reset() {
+	h.clock.Reset()
+	h.health = 1.0
 }
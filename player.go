@@ -154,24 +154,7 @@ func (p *Player) wouldCollideAt(newX, newY float64, grid *tiled.CollisionGrid) b
 	if grid == nil {
 		return false
 	}
-	const tileSize = 32
-
-	x1 := int(newX) / tileSize
-	y1 := int(newY) / tileSize
-	x2 := int(newX+p.w-1) / tileSize
-	y2 := int(newY+p.h-1) / tileSize
-
-	for y := y1; y <= y2; y++ {
-		for x := x1; x <= x2; x++ {
-			if y < 0 || y >= grid.Height || x < 0 || x >= grid.Width {
-				continue
-			}
-			if grid.Solid[y][x] {
-				return true
-			}
-		}
-	}
-	return false
+	return grid.RectCollides(newX, newY, p.w, p.h)
 }
 
 func (p *Player) OnCollision(other Entity, grid *tiled.CollisionGrid) { // Added grid parameter
@@ -0,0 +1,64 @@
+package main
+
+import "time"
+
+// raceTPS is how many race clock ticks make a second. It's fixed (Ebitengine's
+// default TPS) so times only depend on how many updates ran, not on the wall clock.
+const raceTPS = 60
+
+// RaceClock is the race time, counted in Update ticks. RaceScene only ticks it while
+// the race is actually running, so pausing, fading and a backgrounded browser tab
+// don't cost the rider anything. The HUD, rivals, results and split times all read it.
+type RaceClock struct {
+	ticks  int
+	splits []Split
+}
+
+// Split is the moment a checkpoint was checked in, in ticks since the start
+type Split struct {
+	Name string `json:"name"`
+	Tick int    `json:"tick"`
+}
+
+// Seconds is the split's race time
+func (s Split) Seconds() float64 {
+	return float64(s.Tick) / raceTPS
+}
+
+// Tick advances the clock by one update
+func (c *RaceClock) Tick() {
+	c.ticks++
+}
+
+// Ticks is how many updates the race has been running
+func (c *RaceClock) Ticks() int {
+	return c.ticks
+}
+
+// Seconds is the race time in seconds
+func (c *RaceClock) Seconds() float64 {
+	return float64(c.ticks) / raceTPS
+}
+
+// Elapsed is the race time as a Duration (what the map renderer's animations use)
+func (c *RaceClock) Elapsed() time.Duration {
+	return time.Duration(c.ticks) * time.Second / raceTPS
+}
+
+// Split records a checkpoint at the current tick and returns it
+func (c *RaceClock) Split(name string) Split {
+	s := Split{Name: name, Tick: c.ticks}
+	c.splits = append(c.splits, s)
+	return s
+}
+
+// Splits are all check-ins so far, in order
+func (c *RaceClock) Splits() []Split {
+	return c.splits
+}
+
+// Reset puts the clock back to zero and forgets the splits
+func (c *RaceClock) Reset() {
+	c.ticks = 0
+	c.splits = nil
+}
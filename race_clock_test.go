@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRaceClock(t *testing.T) {
+	var c RaceClock
+	for range 90 {
+		c.Tick()
+	}
+	if c.Ticks() != 90 || c.Seconds() != 1.5 || c.Elapsed() != 1500*time.Millisecond {
+		t.Errorf("after 90 ticks: %d ticks, %vs, %v", c.Ticks(), c.Seconds(), c.Elapsed())
+	}
+
+	a := c.Split("A")
+	for range 30 {
+		c.Tick()
+	}
+	c.Split("FINISH")
+	if a.Tick != 90 || a.Seconds() != 1.5 {
+		t.Errorf("split A at tick %d (%vs), want 90", a.Tick, a.Seconds())
+	}
+	if want := []Split{{"A", 90}, {"FINISH", 120}}; !slices.Equal(c.Splits(), want) {
+		t.Errorf("splits = %v, want %v", c.Splits(), want)
+	}
+
+	c.Reset()
+	if c.Ticks() != 0 || len(c.Splits()) != 0 {
+		t.Errorf("after Reset: %d ticks, %d splits", c.Ticks(), len(c.Splits()))
+	}
+}
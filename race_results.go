@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RaceResult is one rider's line in the standings
+type RaceResult struct {
+	Name     string
+	IsPlayer bool
+	Finished bool
+	Time     float64 // seconds on the race clock, only for finishers
+	Stops    int     // checkpoints done, finish line not counted
+	ToGo     float64 // distance to their next stop, breaks ties between riders on the same stop
+	DFL      bool    // "dead last": the slowest rider who still made it to the finish
+}
+
+// Status is what the results table shows in the time column
+func (r RaceResult) Status() string {
+	if r.Finished {
+		return formatRaceTime(r.Time)
+	}
+	return "DNF"
+}
+
+// formatRaceTime turns race clock seconds into MM:SS.cc
+func formatRaceTime(sec float64) string {
+	cs := int(math.Round(sec * 100))
+	return fmt.Sprintf("%02d:%02d.%02d", cs/6000, (cs/100)%60, cs%100)
+}
+
+// sortStandings ranks finishers by time, then everyone else by stops done
+// and how close they are to their next one
+func sortStandings(results []RaceResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Finished != b.Finished {
+			return a.Finished
+		}
+		if a.Finished {
+			return a.Time < b.Time
+		}
+		if a.Stops != b.Stops {
+			return a.Stops > b.Stops
+		}
+		return a.ToGo < b.ToGo
+	})
+}
+
+// standings is the live order of the race, player included
+func (s *RaceScene) standings() []RaceResult {
+	var results []RaceResult
+	if s.manifest == nil {
+		return results
+	}
+
+	// The player
+	px, py := s.player.Center()
+	player := RaceResult{Name: "YOU", IsPlayer: true}
+	for _, cp := range s.manifest.Checkpoints {
+		switch {
+		case cp.IsFinishLine && cp.IsComplete:
+			player.Finished, player.Time = true, s.playerFinishTime
+		case cp.IsComplete:
+			player.Stops++
+		}
+	}
+	player.ToGo = toGo(s.manifest, px, py, player.Stops, func(cp *Checkpoint) bool { return cp.IsComplete })
+	results = append(results, player)
+
+	// The rivals
+	if s.npcManager != nil {
+		for _, n := range s.npcManager.Bikers {
+			r := RaceResult{Name: n.Name, Finished: n.Finished, Time: n.FinalTime}
+			for _, cp := range s.manifest.Checkpoints {
+				if !cp.IsFinishLine && n.Inventory[cp.Name] {
+					r.Stops++
+				}
+			}
+			nx, ny := n.Center()
+			r.ToGo = toGo(s.manifest, nx, ny, r.Stops, func(cp *Checkpoint) bool { return n.Inventory[cp.Name] })
+			results = append(results, r)
+		}
+	}
+
+	sortStandings(results)
+	return results
+}
+
+// toGo is how far (x, y) is from the nearest stop a rider still needs, straight line.
+// The finish line only counts once all the other stops are done.
+func toGo(m *Manifest, x, y float64, stops int, done func(cp *Checkpoint) bool) float64 {
+	best := math.Inf(1)
+	for _, cp := range m.Checkpoints {
+		if !done(cp) && (!cp.IsFinishLine || stops == len(m.Checkpoints)-1) {
+			best = math.Min(best, math.Hypot(cp.X-x, cp.Y-y))
+		}
+	}
+	return best
+}
+
+// finalResults is the standings when the race ends: whoever hasn't finished is a DNF,
+// and the slowest finisher gets the DFL
+func (s *RaceScene) finalResults() []RaceResult {
+	results := s.standings()
+	last := -1
+	for i, r := range results {
+		if r.Finished {
+			last = i
+		}
+	}
+	if last > 0 {
+		results[last].DFL = true
+	}
+	return results
+}
+
+// playerPosition is the player's place in the live standings (1-based) and the field size
+func playerPosition(results []RaceResult) (int, int) {
+	for i, r := range results {
+		if r.IsPlayer {
+			return i + 1, len(results)
+		}
+	}
+	return 0, len(results)
+}
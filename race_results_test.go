@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSortStandings(t *testing.T) {
+	results := []RaceResult{
+		{Name: "stuck", Stops: 0, ToGo: 50},
+		{Name: "slow", Finished: true, Time: 95},
+		{Name: "YOU", IsPlayer: true, Stops: 2, ToGo: 300},
+		{Name: "close", Stops: 2, ToGo: 40},
+		{Name: "fast", Finished: true, Time: 80.5},
+		{Name: "lost", Stops: 0, ToGo: math.Inf(1)},
+	}
+	sortStandings(results)
+
+	want := []string{"fast", "slow", "close", "YOU", "stuck", "lost"}
+	for i, w := range want {
+		if results[i].Name != w {
+			t.Fatalf("place %d is %s, want %s (order %v)", i+1, results[i].Name, w, results)
+		}
+	}
+	if place, field := playerPosition(results); place != 4 || field != 6 {
+		t.Errorf("playerPosition = %d of %d, want 4 of 6", place, field)
+	}
+	if place, _ := playerPosition(results[:2]); place != 0 {
+		t.Errorf("playerPosition without the player = %d", place)
+	}
+}
+
+func TestRaceResultStatus(t *testing.T) {
+	tests := []struct {
+		r    RaceResult
+		want string
+	}{
+		{RaceResult{Finished: true, Time: 0}, "00:00.00"},
+		{RaceResult{Finished: true, Time: 83.456}, "01:23.46"},
+		{RaceResult{Finished: true, Time: 3599.999}, "60:00.00"},
+		{RaceResult{Time: 12}, "DNF"},
+	}
+	for _, tt := range tests {
+		if got := tt.r.Status(); got != tt.want {
+			t.Errorf("Status(%+v) = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// splitFlashTicks is how long the HUD shows a split after a check-in
+const splitFlashTicks = 3 * raceTPS
+
+// SplitLine is one row of the split breakdown
+type SplitLine struct {
+	Name     string
+	Time     float64 // race clock seconds at the check-in
+	Delta    float64 // against the best run's split at the same stop, negative is faster
+	HasDelta bool    // false on a first run or a stop the best run didn't have
+}
+
+// Key names a manifest by its level and checkpoint set, so the same stops and finish
+// line compare against each other no matter what order they were shuffled in
+func (m *Manifest) Key() string {
+	var stops []string
+	finish := ""
+	for _, cp := range m.Checkpoints {
+		if cp.IsFinishLine {
+			finish = cp.Name
+			continue
+		}
+		stops = append(stops, cp.Name)
+	}
+	sort.Strings(stops)
+	return m.Level + ":" + strings.Join(stops, ",") + " > " + finish
+}
+
+// bestSplit finds a stop in a run's splits
+func bestSplit(best []Split, name string) (Split, bool) {
+	for _, s := range best {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Split{}, false
+}
+
+// splitBreakdown lines up a run's splits against the best run on the same manifest.
+// best may be nil.
+func splitBreakdown(splits, best []Split) []SplitLine {
+	lines := make([]SplitLine, 0, len(splits))
+	for _, s := range splits {
+		line := SplitLine{Name: s.Name, Time: s.Seconds()}
+		if b, ok := bestSplit(best, s.Name); ok {
+			line.Delta, line.HasDelta = s.Seconds()-b.Seconds(), true
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatDelta is a signed split difference: +1.20, -12.34, +1:02.50
+func formatDelta(sec float64) string {
+	sign := "+"
+	if sec < 0 {
+		sign = "-"
+	}
+	cs := int(math.Round(math.Abs(sec) * 100))
+	if cs >= 6000 {
+		return fmt.Sprintf("%s%d:%02d.%02d", sign, cs/6000, (cs/100)%60, cs%100)
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cs/100, cs%100)
+}
+
+// runTime is a finished run's time, its last split
+func runTime(splits []Split) float64 {
+	if len(splits) == 0 {
+		return math.Inf(1)
+	}
+	return splits[len(splits)-1].Seconds()
+}
+
+// checkIn records a checkpoint on the race clock and flashes the split on the HUD
+func (s *RaceScene) checkIn(cp *Checkpoint) Split {
+	split := s.clock.Split(cp.Name)
+
+	text := fmt.Sprintf("%s %s", cp.Name, formatRaceTime(split.Seconds()))
+	if b, ok := bestSplit(s.game.save.BestSplits(s.manifest.Key()), cp.Name); ok {
+		text += " " + formatDelta(split.Seconds()-b.Seconds())
+	}
+	s.hud.showSplit(text)
+	return split
+}
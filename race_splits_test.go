@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSplitBreakdown(t *testing.T) {
+	run := []Split{{"A", 600}, {"B", 1200}, {"FINISH", 1500}}
+	best := []Split{{"B", 1260}, {"A", 630}, {"FINISH", 1440}} // took the stops the other way round
+
+	lines := splitBreakdown(run, best)
+	want := []SplitLine{
+		{Name: "A", Time: 10, Delta: -0.5, HasDelta: true},
+		{Name: "B", Time: 20, Delta: -1, HasDelta: true},
+		{Name: "FINISH", Time: 25, Delta: 1, HasDelta: true},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, w := range want {
+		if l := lines[i]; l.Name != w.Name || l.Time != w.Time || l.HasDelta != w.HasDelta || math.Abs(l.Delta-w.Delta) > 1e-9 {
+			t.Errorf("line %d = %+v, want %+v", i, l, w)
+		}
+	}
+
+	// First run, or a stop the best run never went to
+	for _, l := range splitBreakdown(run, nil) {
+		if l.HasDelta {
+			t.Errorf("%s has a delta with no best run", l.Name)
+		}
+	}
+	if l := splitBreakdown([]Split{{"C", 60}}, best); l[0].HasDelta {
+		t.Error("C has a delta, the best run never stopped there")
+	}
+}
+
+func TestFormatDelta(t *testing.T) {
+	tests := []struct {
+		sec  float64
+		want string
+	}{
+		{0, "+0.00"},
+		{1.2, "+1.20"},
+		{-12.345, "-12.35"},
+		{62.5, "+1:02.50"},
+	}
+	for _, tt := range tests {
+		if got := formatDelta(tt.sec); got != tt.want {
+			t.Errorf("formatDelta(%v) = %q, want %q", tt.sec, got, tt.want)
+		}
+	}
+}
+
+func TestManifestKey(t *testing.T) {
+	stops := func(finish string, names ...string) *Manifest {
+		m := &Manifest{}
+		for _, n := range names {
+			m.Checkpoints = append(m.Checkpoints, &Checkpoint{Name: n})
+		}
+		m.Checkpoints = append(m.Checkpoints, &Checkpoint{Name: finish, IsFinishLine: true})
+		return m
+	}
+	if stops("F", "A", "B", "C").Key() != stops("F", "C", "A", "B").Key() {
+		t.Error("the same stops in another order got a different key")
+	}
+	if stops("F", "A", "B").Key() == stops("B", "A", "F").Key() {
+		t.Error("a different finish line got the same key")
+	}
+	if runTime(nil) != math.Inf(1) || runTime([]Split{{"A", 60}, {"F", 120}}) != 2 {
+		t.Error("runTime should be the last split, never finished is forever")
+	}
+}
@@ -40,13 +40,37 @@ func Init(ctx *audio.Context) {
 
 // --- PUBLIC MUSIC API ---
 
+// Song is what changes between the generated tracks: tempo and chords.
+// Drums, arp and lead are the same for all of them.
+type Song struct {
+	Beat        float64   // seconds per beat
+	Progression []float64 // root note of each measure in Hz, looping
+}
+
+// Songs are the tracks levels can ask for by name
+var Songs = map[string]Song{
+	"nyc":    {Beat: 0.4, Progression: []float64{82.41, 98.00, 73.42, 110.00}},  // E, G, D, A
+	"sprint": {Beat: 0.32, Progression: []float64{110.00, 82.41, 98.00, 73.42}}, // A, E, G, D, faster
+}
+
+// Start plays the NYC track
 func Start() {
+	StartSong("nyc")
+}
+
+// StartSong plays a track from Songs, the NYC one if there's no such name
+func StartSong(name string) {
+	song, ok := Songs[name]
+	if !ok {
+		song = Songs["nyc"]
+	}
+
 	mu.Lock()
 	defer mu.Unlock()
 	if playing || context == nil {
 		return
 	}
-	pcm := buildPCM()
+	pcm := buildPCM(song)
 	loop := audio.NewInfiniteLoop(bytes.NewReader(pcm), int64(len(pcm)))
 	var err error
 	player, err = context.NewPlayer(loop)
@@ -68,8 +92,9 @@ func Stop() {
 
 // --- INTERNAL GENERATORS ---
 
-func buildPCM() []byte {
-	beat := 0.4
+func buildPCM(song Song) []byte {
+	beat := song.Beat
+	prog := song.Progression
 	measures := 20
 	spb := int(sampleRate * beat)
 	spm := spb * 8
@@ -78,11 +103,11 @@ func buildPCM() []byte {
 	for m := 0; m < measures; m++ {
 		off := m * spm
 		addDrums(mix, off, spb)
-		addBass(mix, off, m, spb)
+		addBass(mix, off, prog[m%len(prog)], m, spb)
 		if m < 8 {
-			addArp(mix, off, m, spb)
+			addArp(mix, off, prog[m%len(prog)], spb)
 		} else if m < 16 {
-			addLead(mix, off, m, spb)
+			addLead(mix, off, prog[m%len(prog)], spb)
 		}
 		// else {
 		// 	addEbRiff(mix, off, m, spb)
@@ -109,9 +134,7 @@ func addDrums(buf []float64, off, spb int) {
 	}
 }
 
-func addBass(buf []float64, off, m, spb int) {
-	prog := []float64{82.41, 98.00, 73.42, 110.00}
-	root := prog[m%4]
+func addBass(buf []float64, off int, root float64, m, spb int) {
 	if m >= 16 {
 		root = 77.78
 	}
@@ -130,9 +153,7 @@ func addBass(buf []float64, off, m, spb int) {
 	}
 }
 
-func addArp(buf []float64, off, m, spb int) {
-	prog := []float64{82.41, 98.00, 73.42, 110.00} // E, G, D, A
-	root := prog[m%4]
+func addArp(buf []float64, off int, root float64, spb int) {
 
 	// Classic rolling 8th-note pattern
 	arpNotes := []float64{1.0, 1.25, 1.5, 1.25, 2.0, 1.5, 1.25, 1.0}
@@ -153,9 +174,7 @@ func addArp(buf []float64, off, m, spb int) {
 	}
 }
 
-func addLead(buf []float64, off, m, spb int) {
-	prog := []float64{82.41, 98.00, 73.42, 110.00}
-	root := prog[m%4]
+func addLead(buf []float64, off int, root float64, spb int) {
 	for b := 0; b < 8; b++ {
 		start := off + b*spb
 		for i := 0; i < spb && start+i < len(buf); i++ {
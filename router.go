@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// The router owns the scenes. Scenes don't build their successor anymore: they tell
+// the router what happened (EventNext, EventFinish...) and sceneGraph (routes.go)
+// says where that leads and whether to fade there. On top of the current scene sits
+// a stack of overlays (pause, map, dialog) that get the input until they're popped.
+
+// SceneFactory builds the scene for a route. args is whatever the event carried.
+type SceneFactory func(g *Game, args any) Scene
+
+// SceneEnterer is an optional hook, called when a scene becomes current or is pushed
+type SceneEnterer interface {
+	Enter()
+}
+
+// SceneExiter is an optional hook, called when a scene is replaced or popped
+type SceneExiter interface {
+	Exit()
+}
+
+// TextEntry is for scenes that read typed characters (initials, manifest codes).
+// While TakesText is true the game's letter hotkeys (fullscreen, debug) stay off.
+type TextEntry interface {
+	TakesText() bool
+}
+
+// Edge is one arrow of the scene graph
+type Edge struct {
+	From, On, To string
+	Fade         bool // fade out to black, swap, fade back in
+}
+
+// Fade lengths for Edge.Fade, in seconds
+const (
+	fadeOutSeconds = 0.25
+	fadeInSeconds  = 0.5
+)
+
+type Router struct {
+	game   *Game
+	routes map[string]SceneFactory
+	edges  map[[2]string]Edge // by From, On
+
+	route string  // name of the current scene's route
+	stack []Scene // [0] is the current scene, the rest are overlays, top last
+
+	fader   *Fader
+	pending func() // the swap waiting for the fade out to finish
+}
+
+func NewRouter(game *Game, edges []Edge) *Router {
+	r := &Router{
+		game:   game,
+		routes: map[string]SceneFactory{},
+		edges:  map[[2]string]Edge{},
+	}
+	for _, e := range edges {
+		r.edges[[2]string{e.From, e.On}] = e
+	}
+	return r
+}
+
+// Register names a scene
+func (r *Router) Register(name string, build SceneFactory) {
+	r.routes[name] = build
+}
+
+// Route is the name of the current scene's route
+func (r *Router) Route() string {
+	return r.route
+}
+
+// Follow takes the edge for an event out of the current route
+func (r *Router) Follow(event string, args any) {
+	e, ok := r.edges[[2]string{r.route, event}]
+	if !ok {
+		panic(fmt.Sprintf("router: no edge from %q on %q", r.route, event))
+	}
+	r.Go(e.To, args, e.Fade)
+}
+
+// Go swaps every scene (overlays too) for a route, fading if asked to.
+// While fading out nothing gets updated, the old scene just sits there getting darker.
+func (r *Router) Go(name string, args any, fade bool) {
+	build, ok := r.routes[name]
+	if !ok {
+		panic(fmt.Sprintf("router: no route %q", name))
+	}
+	swap := func() {
+		for len(r.stack) > 0 {
+			r.Pop()
+		}
+		r.route = name
+		r.enter(build(r.game, args))
+	}
+
+	if !fade {
+		r.fader, r.pending = nil, nil
+		swap()
+		return
+	}
+	r.fader = NewFader(FadeOut, fadeOutSeconds)
+	r.pending = swap
+}
+
+// Push puts an overlay on top. The scenes under it keep drawing but stop updating.
+func (r *Router) Push(s Scene) {
+	r.enter(s)
+}
+
+// Pop takes the top scene off (the overlay, or the current scene if there's none)
+func (r *Router) Pop() {
+	if len(r.stack) == 0 {
+		return
+	}
+	top := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	if ex, ok := top.(SceneExiter); ok {
+		ex.Exit()
+	}
+}
+
+// Top is the scene getting the input
+func (r *Router) Top() Scene {
+	if len(r.stack) == 0 {
+		return nil
+	}
+	return r.stack[len(r.stack)-1]
+}
+
+// TakesText is true while the top scene is busy with typing, see TextEntry
+func (r *Router) TakesText() bool {
+	te, ok := r.Top().(TextEntry)
+	return ok && te.TakesText()
+}
+
+func (r *Router) enter(s Scene) {
+	r.stack = append(r.stack, s)
+	if en, ok := s.(SceneEnterer); ok {
+		en.Enter()
+	}
+}
+
+func (r *Router) Update() error {
+	if r.fader != nil {
+		r.fader.Update()
+		if r.pending != nil {
+			if !r.fader.Finished {
+				return nil
+			}
+			r.pending()
+			r.pending = nil
+			r.fader = NewFader(FadeIn, fadeInSeconds)
+		} else if r.fader.Finished {
+			r.fader = nil
+		}
+	}
+
+	if top := r.Top(); top != nil {
+		return top.Update()
+	}
+	return nil
+}
+
+func (r *Router) Draw(screen *ebiten.Image) {
+	for _, s := range r.stack {
+		s.Draw(screen)
+	}
+	if r.fader != nil {
+		r.fader.Draw(screen)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// logScene writes what happens to it into a shared log
+type logScene struct {
+	name string
+	log  *[]string
+}
+
+func (s *logScene) Enter()                    { *s.log = append(*s.log, "enter "+s.name) }
+func (s *logScene) Exit()                     { *s.log = append(*s.log, "exit "+s.name) }
+func (s *logScene) Update() error             { *s.log = append(*s.log, "update "+s.name); return nil }
+func (s *logScene) Draw(screen *ebiten.Image) {}
+
+// logRouter has routes a and b, a --next--> b and a --fade--> b with a fade
+func logRouter(log *[]string) (*Router, *[]any) {
+	r := NewRouter(&Game{}, []Edge{
+		{From: "a", On: EventNext, To: "b"},
+		{From: "a", On: "fade", To: "b", Fade: true},
+	})
+	var args []any
+	for _, name := range []string{"a", "b"} {
+		r.Register(name, func(_ *Game, a any) Scene {
+			args = append(args, a)
+			return &logScene{name: name, log: log}
+		})
+	}
+	return r, &args
+}
+
+func TestRouterFollow(t *testing.T) {
+	var log []string
+	r, args := logRouter(&log)
+	r.Go("a", "hello", false)
+	r.Push(&logScene{name: "pause", log: &log})
+	r.Update()
+	r.Follow(EventNext, 42)
+	r.Update()
+
+	want := []string{"enter a", "enter pause", "update pause", "exit pause", "exit a", "enter b", "update b"}
+	if !slices.Equal(log, want) {
+		t.Errorf("log = %v\nwant %v", log, want)
+	}
+	if r.Route() != "b" || len(r.stack) != 1 {
+		t.Errorf("on %q with %d scenes, want b alone", r.Route(), len(r.stack))
+	}
+	if fmt.Sprint(*args) != "[hello 42]" {
+		t.Errorf("factories got %v", *args)
+	}
+}
+
+func TestRouterPushPop(t *testing.T) {
+	var log []string
+	r, _ := logRouter(&log)
+	r.Go("a", nil, false)
+	pause := &logScene{name: "pause", log: &log}
+	r.Push(pause)
+	if r.Top() != pause {
+		t.Fatal("pushed overlay isn't on top")
+	}
+	r.Pop()
+	r.Update()
+	r.Pop()
+	r.Pop() // nothing left, nothing happens
+	if r.Top() != nil || r.Update() != nil {
+		t.Error("empty router should have no top scene")
+	}
+
+	want := []string{"enter a", "enter pause", "exit pause", "update a", "exit a"}
+	if !slices.Equal(log, want) {
+		t.Errorf("log = %v\nwant %v", log, want)
+	}
+}
+
+func TestRouterFade(t *testing.T) {
+	var log []string
+	r, _ := logRouter(&log)
+	r.Go("a", nil, false)
+	r.Follow("fade", nil)
+
+	// Nothing updates while fading out, then b comes in and updates while fading in
+	outTicks := 0
+	for r.Route() == "a" {
+		if outTicks++; outTicks > 60 {
+			t.Fatal("fade out never finished")
+		}
+		r.Update()
+	}
+	if n := int(60 * fadeOutSeconds); outTicks < n || outTicks > n+2 {
+		t.Errorf("swapped after %d ticks, want about %d", outTicks, n)
+	}
+	if want := []string{"enter a", "exit a", "enter b", "update b"}; !slices.Equal(log, want) {
+		t.Errorf("log = %v\nwant %v", log, want)
+	}
+	for range int(60*fadeInSeconds) + 2 {
+		r.Update()
+	}
+	if r.fader != nil {
+		t.Error("fade in never finished")
+	}
+
+	// A plain Go in the middle of a fade wins
+	r.Go("a", nil, false)
+	r.Follow("fade", nil)
+	r.Go("a", nil, false)
+	if r.fader != nil || r.pending != nil {
+		t.Error("Go left the fade running")
+	}
+}
+
+func TestRouterMissing(t *testing.T) {
+	var log []string
+	r, _ := logRouter(&log)
+	r.Go("b", nil, false)
+	for name, f := range map[string]func(){
+		"edge":  func() { r.Follow(EventNext, nil) },
+		"route": func() { r.Go("nowhere", nil, false) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("missing %s didn't panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+// Every scene in the game, and how they hang together. Adding a scene is a route,
+// a factory in registerRoutes and whatever edges lead in and out of it.
+//
+//	title → stage select → level title → manifest → race → end → title
+//	                                                  ↓ ↑
+//	                                               game over
+
+// Route names
+const (
+	RouteTitle       = "title"
+	RouteStageSelect = "stage_select"
+	RouteLevelTitle  = "level_title" // args: *Level
+	RouteManifest    = "manifest"
+	RouteRace        = "race"      // args: *Manifest
+	RouteEnd         = "end"       // args: endArgs
+	RouteGameOver    = "game_over" // args: *Manifest
+)
+
+// Events a scene can hand the router
+const (
+	EventNext   = "next"   // done here, on to whatever's next
+	EventFinish = "finish" // crossed the finish line
+	EventQuit   = "quit"   // bailed out of the race
+	EventCrash  = "crash"  // ended up in the hospital
+)
+
+// sceneGraph is the game's flow, see Router.Follow
+var sceneGraph = []Edge{
+	{From: RouteTitle, On: EventNext, To: RouteStageSelect},
+	{From: RouteStageSelect, On: EventNext, To: RouteLevelTitle},
+	{From: RouteLevelTitle, On: EventNext, To: RouteManifest},
+	{From: RouteManifest, On: EventNext, To: RouteRace, Fade: true},
+	{From: RouteRace, On: EventFinish, To: RouteEnd, Fade: true},
+	{From: RouteRace, On: EventQuit, To: RouteEnd, Fade: true},
+	{From: RouteRace, On: EventCrash, To: RouteGameOver},
+	{From: RouteGameOver, On: EventNext, To: RouteRace, Fade: true}, // same manifest again
+	{From: RouteEnd, On: EventNext, To: RouteTitle},
+}
+
+// endArgs is what the end screen needs from the race
+type endArgs struct {
+	cash     int
+	results  []RaceResult
+	splits   []SplitLine
+	manifest *Manifest
+}
+
+func registerRoutes(r *Router) {
+	r.Register(RouteTitle, func(g *Game, _ any) Scene { return NewTitleScene(g) })
+	r.Register(RouteStageSelect, func(g *Game, _ any) Scene { return NewStageSelectScene(g) })
+	r.Register(RouteLevelTitle, func(g *Game, args any) Scene { return NewLevelTitleScene(g, args.(*Level)) })
+	r.Register(RouteManifest, func(g *Game, _ any) Scene { return NewGetManifestScene(g) })
+	r.Register(RouteRace, func(g *Game, args any) Scene { return NewRaceScene(g, args.(*Manifest)) })
+	r.Register(RouteEnd, func(g *Game, args any) Scene {
+		a := args.(endArgs)
+		return NewEndScene(g, a.cash, a.results, a.splits, a.manifest)
+	})
+	r.Register(RouteGameOver, func(g *Game, args any) Scene { return NewGameOverScene(g, args.(*Manifest)) })
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Save data lives behind a tiny key/value Storage so the same code runs everywhere:
+// a file in the user config dir on desktop (save_desktop.go), localStorage in the
+// browser (save_web.go). Everything is one JSON blob under saveKey.
+
+// Storage is where save data survives restarts
+type Storage interface {
+	Load(key string) ([]byte, error) // nil, nil when nothing was saved yet
+	Store(key string, data []byte) error
+}
+
+const (
+	saveKey      = "alley_cat_1999"
+	maxBestTimes = 5 // entries kept per manifest leaderboard
+)
+
+// SaveData is everything we remember between runs
+type SaveData struct {
+	Initials   string                     `json:"initials"`   // last ones entered, pre-filled next time
+	TotalCash  int                        `json:"total_cash"` // every race's cash, banked
+	Difficulty Difficulty                 `json:"difficulty"` // last picked on the manifest screen
+	Level      string                     `json:"level"`      // last stage picked, by Level.ID
+	Cleared    map[string]bool            `json:"cleared"`    // stages the player finished, unlocks the next one
+	Manifests  map[string]*ManifestRecord `json:"manifests"`  // by Manifest.Key
+}
+
+// ManifestRecord is the leaderboard for one checkpoint set
+type ManifestRecord struct {
+	BestSplits []Split    `json:"best_splits"` // the fastest finished run, for split deltas
+	Times      []BestTime `json:"times"`       // fastest first, at most maxBestTimes
+}
+
+// BestTime is one leaderboard entry
+type BestTime struct {
+	Initials string  `json:"initials"`
+	Time     float64 `json:"time"`
+}
+
+// memoryStorage keeps saves for this session only, for when there's nowhere better
+type memoryStorage map[string][]byte
+
+func (m memoryStorage) Load(key string) ([]byte, error) { return m[key], nil }
+
+func (m memoryStorage) Store(key string, data []byte) error {
+	m[key] = data
+	return nil
+}
+
+// LoadSave reads the save, starting fresh if there's none or it's unreadable
+func LoadSave(st Storage) *SaveData {
+	d := &SaveData{}
+	data, err := st.Load(saveKey)
+	if err != nil {
+		fmt.Printf("DEBUG ERROR: could not load save: %v\n", err)
+	} else if data != nil {
+		if err := json.Unmarshal(data, d); err != nil {
+			fmt.Printf("DEBUG ERROR: save is corrupt, starting over: %v\n", err)
+			d = &SaveData{}
+		}
+	}
+	if d.Manifests == nil {
+		d.Manifests = map[string]*ManifestRecord{}
+	}
+	if d.Cleared == nil {
+		d.Cleared = map[string]bool{}
+	}
+	return d
+}
+
+// Write stores the save
+func (d *SaveData) Write(st Storage) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return st.Store(saveKey, data)
+}
+
+// Record returns the leaderboard for a manifest key, creating it if needed
+func (d *SaveData) Record(key string) *ManifestRecord {
+	r, ok := d.Manifests[key]
+	if !ok {
+		r = &ManifestRecord{}
+		d.Manifests[key] = r
+	}
+	return r
+}
+
+// BestSplits is the fastest run's splits on a manifest, nil if it was never finished
+func (d *SaveData) BestSplits(key string) []Split {
+	if r, ok := d.Manifests[key]; ok {
+		return r.BestSplits
+	}
+	return nil
+}
+
+// RecordRun keeps the player's splits if it's their fastest finish on this manifest.
+// Returns the previous best (nil on a first finish) so the end screen can still compare.
+func (d *SaveData) RecordRun(key string, splits []Split) []Split {
+	r := d.Record(key)
+	prev := r.BestSplits
+	if prev == nil || runTime(splits) < runTime(prev) {
+		r.BestSplits = append([]Split(nil), splits...)
+	}
+	return prev
+}
+
+// Qualifies says if a time makes the leaderboard
+func (r *ManifestRecord) Qualifies(t float64) bool {
+	return len(r.Times) < maxBestTimes || t < r.Times[len(r.Times)-1].Time
+}
+
+// AddTime puts a time on the leaderboard. Returns its place (0-based), -1 if it didn't make it.
+func (r *ManifestRecord) AddTime(initials string, t float64) int {
+	if !r.Qualifies(t) {
+		return -1
+	}
+	i := sort.Search(len(r.Times), func(i int) bool { return r.Times[i].Time > t })
+	r.Times = append(r.Times, BestTime{})
+	copy(r.Times[i+1:], r.Times[i:])
+	r.Times[i] = BestTime{Initials: initials, Time: t}
+	if len(r.Times) > maxBestTimes {
+		r.Times = r.Times[:maxBestTimes]
+	}
+	return i
+}
+
+// persist writes the save, a failed write only costs the high scores so it's just logged
+func (g *Game) persist() {
+	if err := g.save.Write(g.storage); err != nil {
+		fmt.Printf("DEBUG ERROR: could not write save: %v\n", err)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !js && !wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// fileStorage keeps each key in a JSON file, e.g. ~/.config/alley_cat_1999/alley_cat_1999.json
+type fileStorage struct {
+	dir string
+}
+
+func newStorage() Storage {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		fmt.Printf("DEBUG ERROR: no config dir, saves won't survive a restart: %v\n", err)
+		return memoryStorage{}
+	}
+	return fileStorage{dir: filepath.Join(dir, "alley_cat_1999")}
+}
+
+func (f fileStorage) path(key string) string {
+	return filepath.Join(f.dir, key+".json")
+}
+
+func (f fileStorage) Load(key string) ([]byte, error) {
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (f fileStorage) Store(key string, data []byte) error {
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return err
+	}
+	// Write next to it and rename, so a crash mid-write can't eat the old save
+	tmp := f.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(key))
+}
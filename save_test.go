@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestAddTime(t *testing.T) {
+	r := &ManifestRecord{}
+	for i, tm := range []float64{50, 30, 40, 60, 20} {
+		if !r.Qualifies(tm) {
+			t.Errorf("entry %d: %v doesn't qualify on a board with room", i, tm)
+		}
+		r.AddTime("AAA", tm)
+	}
+	var got []float64
+	for _, bt := range r.Times {
+		got = append(got, bt.Time)
+	}
+	if want := []float64{20, 30, 40, 50, 60}; !slices.Equal(got, want) {
+		t.Fatalf("times = %v, want %v", got, want)
+	}
+
+	// Full board: only beating the last place counts, and it falls off
+	if r.Qualifies(60) || r.AddTime("SLO", 61) != -1 {
+		t.Error("a time no better than last place made the full board")
+	}
+	if place := r.AddTime("NEW", 35); place != 2 {
+		t.Errorf("35s placed %d, want 2", place)
+	}
+	if len(r.Times) != maxBestTimes || r.Times[2].Initials != "NEW" || r.Times[maxBestTimes-1].Time != 50 {
+		t.Errorf("board after 35s: %+v", r.Times)
+	}
+
+	// Ties go after the time already there
+	if place := r.AddTime("TIE", 30); place != 2 || r.Times[1].Initials != "AAA" {
+		t.Errorf("tie placed %d: %+v", place, r.Times)
+	}
+}
+
+func TestRecordRun(t *testing.T) {
+	d := LoadSave(memoryStorage{})
+	slow := []Split{{Name: "A", Tick: 600}, {Name: "FINISH", Tick: 1200}}
+	fast := []Split{{Name: "A", Tick: 500}, {Name: "FINISH", Tick: 900}}
+
+	if prev := d.RecordRun("k", slow); prev != nil {
+		t.Errorf("first finish had a previous best: %v", prev)
+	}
+	if prev := d.RecordRun("k", fast); !slices.Equal(prev, slow) {
+		t.Errorf("previous best = %v, want the slow run", prev)
+	}
+	if prev := d.RecordRun("k", slow); !slices.Equal(prev, fast) || !slices.Equal(d.BestSplits("k"), fast) {
+		t.Error("a slower run replaced the best splits")
+	}
+	if d.BestSplits("other") != nil {
+		t.Error("best splits on a manifest that was never raced")
+	}
+}
+
+// failingStorage can't read anything
+type failingStorage struct{}
+
+func (failingStorage) Load(string) ([]byte, error) { return nil, errors.New("disk on fire") }
+func (failingStorage) Store(string, []byte) error  { return errors.New("disk on fire") }
+
+func TestSaveRoundTrip(t *testing.T) {
+	st := memoryStorage{}
+	d := LoadSave(st)
+	d.Initials = "NYC"
+	d.TotalCash = 420
+	d.Record("k").AddTime("NYC", 99.5)
+	if err := d.Write(st); err != nil {
+		t.Fatal(err)
+	}
+
+	got := LoadSave(st)
+	if got.Initials != "NYC" || got.TotalCash != 420 {
+		t.Errorf("read back %+v", got)
+	}
+	if times := got.Record("k").Times; len(times) != 1 || times[0] != (BestTime{"NYC", 99.5}) {
+		t.Errorf("leaderboard read back as %+v", times)
+	}
+
+	// A broken or missing save is a fresh one, never nil maps
+	for name, st := range map[string]Storage{
+		"empty":   memoryStorage{},
+		"corrupt": memoryStorage{saveKey: []byte("{not json")},
+		"failing": failingStorage{},
+	} {
+		d := LoadSave(st)
+		if d.Initials != "" || d.TotalCash != 0 || d.Manifests == nil {
+			t.Errorf("%s: got %+v, want a fresh save", name, d)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+//go:build js || wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// webStorage is the browser's localStorage
+type webStorage struct {
+	ls js.Value
+}
+
+func newStorage() (st Storage) {
+	// Touching localStorage throws in some private modes and sandboxed iframes (itch.io!)
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("DEBUG ERROR: no localStorage, saves won't survive a reload: %v\n", r)
+			st = memoryStorage{}
+		}
+	}()
+	ls := js.Global().Get("localStorage")
+	if !ls.Truthy() {
+		return memoryStorage{}
+	}
+	return webStorage{ls: ls}
+}
+
+func (w webStorage) Load(key string) (data []byte, err error) {
+	defer catchJS(&err)
+	v := w.ls.Call("getItem", key)
+	if v.IsNull() || v.IsUndefined() {
+		return nil, nil
+	}
+	return []byte(v.String()), nil
+}
+
+func (w webStorage) Store(key string, data []byte) (err error) {
+	defer catchJS(&err)
+	w.ls.Call("setItem", key, string(data)) // throws when the quota is full
+	return nil
+}
+
+// catchJS turns a JavaScript exception (a panic in syscall/js) into an error
+func catchJS(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("localStorage: %v", r)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/ngolebiewski/alley_cat_1999/retrotrack"
+)
+
+// Card is a title card: a picture and/or some text, then Confirm, a click or a tap
+// moves on. The title, the level titles and game over are all just Cards (see their
+// scene_*.go), so a new cutscene is a Card plus a route.
+type Card struct {
+	Background color.Color // nil leaves the screen black
+	Image      *ebiten.Image
+	Align      CardAlign
+	Text       []TextBlock
+
+	MinTicks int    // ignores input until it's been up this long, so one click can't skip it
+	Music    string // a retrotrack.Songs name started on enter, "" leaves the music alone
+	OnEnter  func() // anything else to do when the card comes up
+	Sound    func() // played on advance, nil for silence
+
+	// What to tell the router when it's dismissed (see sceneGraph), EventNext if empty,
+	// and the args for the scene it leads to
+	Next string
+	Args any
+
+	// OnAdvance runs right before moving on, touched says it was a tap
+	OnAdvance func(touched bool)
+}
+
+// CardAlign is where the card's image goes
+type CardAlign int
+
+const (
+	AlignCenter CardAlign = iota
+	AlignBottom           // centered across, cardMargin off the bottom
+)
+
+const cardMargin = 10
+
+// TextBlock is DebugPrint text at a screen position
+type TextBlock struct {
+	Text string
+	X, Y int
+}
+
+type CardScene struct {
+	game  *Game
+	card  Card
+	ticks int
+}
+
+func NewCardScene(game *Game, card Card) *CardScene {
+	if card.Next == "" {
+		card.Next = EventNext
+	}
+	return &CardScene{game: game, card: card}
+}
+
+func (s *CardScene) Enter() {
+	if s.card.Music != "" {
+		retrotrack.StartSong(s.card.Music)
+	}
+	if s.card.OnEnter != nil {
+		s.card.OnEnter()
+	}
+}
+
+func (s *CardScene) Update() error {
+	s.ticks++
+	if s.ticks <= s.card.MinTicks {
+		return nil
+	}
+
+	taps := s.game.input.Taps()
+	if len(taps) > 0 || s.game.input.JustPressed(ActionConfirm) {
+		touched := false
+		for _, t := range taps {
+			touched = touched || t.Touch
+		}
+		if s.card.OnAdvance != nil {
+			s.card.OnAdvance(touched)
+		}
+		if s.card.Sound != nil {
+			s.card.Sound()
+		}
+		s.game.router.Follow(s.card.Next, s.card.Args)
+	}
+	return nil
+}
+
+func (s *CardScene) Draw(screen *ebiten.Image) {
+	if s.card.Background != nil {
+		screen.Fill(s.card.Background)
+	}
+
+	if img := s.card.Image; img != nil {
+		size := img.Bounds().Size()
+		y := (screenHeight - size.Y) / 2
+		if s.card.Align == AlignBottom {
+			y = screenHeight - size.Y - cardMargin
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64((screenWidth-size.X)/2), float64(y))
+		screen.DrawImage(img, op)
+	}
+
+	for _, t := range s.card.Text {
+		ebitenutil.DebugPrintAt(screen, t.Text, t.X, t.Y)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestNewCardScene(t *testing.T) {
+	if s := NewCardScene(&Game{}, Card{}); s.card.Next != EventNext {
+		t.Errorf("Next = %q, want %q by default", s.card.Next, EventNext)
+	}
+	if s := NewCardScene(&Game{}, Card{Next: EventQuit}); s.card.Next != EventQuit {
+		t.Errorf("Next = %q, want the card's own", s.card.Next)
+	}
+
+	entered := 0
+	s := NewCardScene(&Game{}, Card{OnEnter: func() { entered++ }})
+	s.Enter()
+	if entered != 1 {
+		t.Errorf("OnEnter ran %d times", entered)
+	}
+	NewCardScene(&Game{}, Card{}).Enter() // no music, no OnEnter, nothing to trip on
+}
+
+func TestGameOverCard(t *testing.T) {
+	m := &Manifest{Checkpoints: []*Checkpoint{{Name: "A", IsComplete: true}, {Name: "B", IsFinishLine: true, IsComplete: true}}}
+	s := NewGameOverScene(&Game{}, m)
+	s.Enter()
+	for _, cp := range m.Checkpoints {
+		if cp.IsComplete {
+			t.Errorf("%s still checked in, the retry starts the manifest over", cp.Name)
+		}
+	}
+	if s.card.Args != m || s.card.MinTicks == 0 {
+		t.Errorf("game over card: args %v, MinTicks %d, want the same manifest and a pause", s.card.Args, s.card.MinTicks)
+	}
+}
@@ -2,59 +2,198 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/ngolebiewski/alley_cat_1999/retrotrack"
 )
 
+const lineHeight = 16 // DebugPrint's font
+
+// EndScene is a card with the results drawn over it, the card handles moving on
+// once the initials (if any) are in
 type EndScene struct {
+	*CardScene
 	game     *Game
-	time     string
 	cash     int
-	touchIDs []ebiten.TouchID
+	results  []RaceResult // final standings, best first
+	splits   []SplitLine  // the player's check-ins, against their best run
+	manifest *Manifest
+	record   *ManifestRecord
+
+	// Initials entry, when the player's time makes the manifest's leaderboard
+	entering bool
+	initials []rune
+	saved    string // the saved initials, shown until the first key and used if none are typed
+	runes    []rune
+	yourTime float64
+	newRank  int // place on the leaderboard once entered, -1 if none
 }
 
-func NewEndScene(game *Game, time string, cash int) *EndScene {
-	return &EndScene{game: game,
-		time: time,
-		cash: cash,
+func NewEndScene(game *Game, cash int, results []RaceResult, splits []SplitLine, manifest *Manifest) *EndScene {
+	s := &EndScene{
+		CardScene: NewCardScene(game, Card{Sound: retrotrack.PlayCityStartSound}),
+		game:      game,
+		cash:      cash,
+		results:   results,
+		splits:    splits,
+		manifest:  manifest,
+		record:    game.save.Record(manifest.Key()),
+		newRank:   -1,
 	}
+	for _, r := range results {
+		if r.IsPlayer && r.Finished && s.record.Qualifies(r.Time) {
+			s.entering = true
+			s.yourTime = r.Time
+			s.saved = game.save.Initials
+		}
+	}
+	return s
 }
 
 func (s *EndScene) Update() error {
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButton0) {
-		{
-			retrotrack.PlayCityStartSound()
-			s.game.scene = NewTitleScene(s.game)
+	if s.entering {
+		s.updateInitials()
+		return nil
+	}
+	return s.CardScene.Update()
+}
+
+// TakesText keeps the F/D hotkeys off while initials are being typed
+func (s *EndScene) TakesText() bool {
+	return s.entering
+}
+
+// updateInitials is arcade style: type up to 3 letters (or UP/DOWN to roll the last one),
+// BACKSPACE to fix, ENTER or a tap to sign the leaderboard
+func (s *EndScene) updateInitials() {
+	s.runes = ebiten.AppendInputChars(s.runes[:0])
+	s.typeInitials(s.runes)
+	in := s.game.input
+	if in.JustPressed(ActionErase) && len(s.initials) > 0 {
+		s.initials = s.initials[:len(s.initials)-1]
+	}
+	if _, y := menuFlick(in); y != 0 {
+		if len(s.initials) == 0 {
+			s.initials = append(s.initials, 'A')
+		} else {
+			step := rune(1)
+			if y > 0 {
+				step = 25
+			}
+			last := &s.initials[len(s.initials)-1]
+			*last = 'A' + (*last-'A'+step)%26
 		}
+	}
 
+	if in.JustPressed(ActionConfirm) || len(in.Taps()) > 0 {
+		if len(s.initials) == 0 {
+			s.initials = []rune(s.saved)
+		}
+		if len(s.initials) == 0 {
+			s.initials = []rune("???")
+		}
+		s.entering = false
+		s.game.save.Initials = string(s.initials)
+		s.newRank = s.record.AddTime(string(s.initials), s.yourTime)
+		s.game.persist()
+		retrotrack.PlayManifestSound()
 	}
-	s.touchIDs = inpututil.AppendJustPressedTouchIDs(s.touchIDs[:0])
-	if len(s.touchIDs) > 0 {
-		retrotrack.PlayCityStartSound()
-		s.game.scene = NewTitleScene(s.game)
+}
+
+// typeInitials adds typed letters and digits, lower case goes up, the rest is dropped
+func (s *EndScene) typeInitials(rs []rune) {
+	for _, r := range rs {
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if ((r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) && len(s.initials) < 3 {
+			s.initials = append(s.initials, r)
+		}
 	}
-	return nil
 }
 
 func (s *EndScene) Draw(screen *ebiten.Image) {
-	results := fmt.Sprintf(
-		"**GREAT RACING!**\n\n"+
-			"YOUR TIME: %s\n"+
-			"CASH EARNED: $%d\n\n"+
-			"--- Leaderboard ---\n"+
-			"CC 01:10:18\n"+
-			"AL 01:10:19\n"+
-			"NG 01:30:45\n"+
-			"HH 01:34:12\n"+
-			"DFL: DT\n\n"+
-			"Press [ENTER] to Restart\n"+
-			"Game by Nick Golebiewski\n"+
-			"https://github.com/ngolebiewski/alley_cat_1999",
-		s.time, s.cash,
+	yourTime := "DNF"
+	var board strings.Builder
+	board.WriteString("--- This Race ---\n")
+	dfl := ""
+	for i, r := range s.results {
+		if r.IsPlayer {
+			yourTime = r.Status()
+		}
+		fmt.Fprintf(&board, "%d. %-13s %s\n", i+1, r.Name, r.Status())
+		if r.DFL {
+			dfl = r.Name
+		}
+	}
+	if dfl != "" {
+		fmt.Fprintf(&board, "DFL: %s\n", dfl)
+	}
+
+	header := fmt.Sprintf(
+		"**GREAT RACING!**  %s  PAR %s\n"+
+			"TIME %s  CASH +$%d  BANK $%d",
+		s.manifest.Difficulty, formatRaceTime(s.manifest.Par), yourTime, s.cash, s.game.save.TotalCash,
 	)
+	ebitenutil.DebugPrint(screen, header)
+	ebitenutil.DebugPrintAt(screen, board.String(), 0, 2*lineHeight)
+	s.drawSplits(screen)
+	s.drawBestTimes(screen, 9*lineHeight)
+
+	footer := "Press [ENTER] to Restart\n"
+	if s.entering {
+		footer = fmt.Sprintf("TOP %d TIME! INITIALS: %s_ [ENTER]\n", maxBestTimes, string(s.initials))
+		if len(s.initials) == 0 && s.saved != "" {
+			footer = fmt.Sprintf("TOP %d TIME! INITIALS: _ [ENTER] FOR %s\n", maxBestTimes, s.saved)
+		}
+	}
+	footer += "Game by Nick Golebiewski\n" +
+		"https://github.com/ngolebiewski/alley_cat_1999"
+	ebitenutil.DebugPrintAt(screen, footer, 0, 12*lineHeight)
+}
+
+// drawBestTimes is the manifest's leaderboard, from the save, three to a line
+func (s *EndScene) drawBestTimes(screen *ebiten.Image, y int) {
+	var b strings.Builder
+	b.WriteString("--- Best Times (this manifest) ---\n")
+	if len(s.record.Times) == 0 {
+		b.WriteString("nobody yet, finish to set one!")
+	}
+	for i, t := range s.record.Times {
+		mark := "."
+		if i == s.newRank {
+			mark = "*" // the one you just set
+		}
+		fmt.Fprintf(&b, "%d%s%-3s %s  ", i+1, mark, t.Initials, formatRaceTime(t.Time))
+		if i%3 == 2 {
+			b.WriteString("\n")
+		}
+	}
+	ebitenutil.DebugPrintAt(screen, b.String(), 0, y)
+}
 
-	ebitenutil.DebugPrint(screen, results)
+// drawSplits is the split breakdown, in a column right of the leaderboard
+func (s *EndScene) drawSplits(screen *ebiten.Image) {
+	if len(s.splits) == 0 {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("--- Splits ---\n")
+	for i, l := range s.splits {
+		if i == 6 {
+			break // the rest would run into the best times
+		}
+		name := l.Name
+		if len(name) > 9 {
+			name = name[:9]
+		}
+		fmt.Fprintf(&b, "%-9s %s", name, formatRaceTime(l.Time))
+		if l.HasDelta {
+			b.WriteString(" " + formatDelta(l.Delta))
+		}
+		b.WriteString("\n")
+	}
+	ebitenutil.DebugPrintAt(screen, b.String(), 168*zoom, 2*lineHeight)
 }
@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestEndSceneInitials(t *testing.T) {
+	confirm := InputFrame{Held: []Action{ActionConfirm}}
+	erase := InputFrame{Held: []Action{ActionErase}}
+	tests := []struct {
+		name   string
+		saved  string
+		typed  string // typed before the scripted frames
+		frames []InputFrame
+		want   string
+	}{
+		{"saved ones kept", "NYC", "", []InputFrame{confirm}, "NYC"},
+		{"first key replaces them", "NYC", "b", []InputFrame{confirm}, "B"},
+		{"typed over", "NYC", "zo3x", []InputFrame{confirm}, "ZO3"},
+		{"erased back to the saved ones", "NYC", "q", []InputFrame{erase, {}, confirm}, "NYC"},
+		{"roll from empty", "NYC", "", []InputFrame{{SteerY: -1}, {}, {SteerY: -1}, confirm}, "B"},
+		{"nothing saved", "", "", []InputFrame{confirm}, "???"},
+		{"symbols dropped", "", "*a-", []InputFrame{{Taps: []Tap{{X: 1, Y: 1}}}}, "A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Game{
+				input:   &ScriptedInput{Frames: tt.frames},
+				storage: memoryStorage{},
+				save:    LoadSave(memoryStorage{}),
+			}
+			g.save.Initials = tt.saved
+			m := &Manifest{Checkpoints: []*Checkpoint{{Name: "A"}, {Name: "B", IsFinishLine: true}}}
+			results := []RaceResult{{Name: "You", IsPlayer: true, Finished: true, Time: 42}}
+
+			s := NewEndScene(g, 0, results, nil, m)
+			if !s.entering || len(s.initials) != 0 {
+				t.Fatalf("entering %v with %q filled in, want an empty field", s.entering, string(s.initials))
+			}
+			s.typeInitials([]rune(tt.typed))
+			for range tt.frames {
+				g.input.Update()
+				s.updateInitials()
+			}
+
+			if s.entering {
+				t.Fatal("still entering initials")
+			}
+			if g.save.Initials != tt.want {
+				t.Errorf("saved initials %q, want %q", g.save.Initials, tt.want)
+			}
+			if times := s.record.Times; len(times) != 1 || times[0].Initials != tt.want || s.newRank != 0 {
+				t.Errorf("leaderboard %+v, rank %d", times, s.newRank)
+			}
+		})
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"image"
 	"image/color"
 	_ "image/png"
+	"strings"
+	"unicode"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/ngolebiewski/alley_cat_1999/retrotrack"
 	"github.com/ngolebiewski/alley_cat_1999/tiled"
@@ -22,36 +23,147 @@ type GetManifestScene struct {
 	game           *Game
 	tileset        *ebiten.Image
 	manifestImg    *ebiten.Image
-	touchIDs       []ebiten.TouchID
 	animTime       float64 // seconds elapsed
 	animDone       bool    // stop animating after 1 second
 	activeManifest *Manifest
+	mapData        *tiled.Map // kept to rebuild the manifest from a typed-in code
+	spawns         tiled.RaceSpawns
+
+	// Manifest code entry: (C) to type one, or paste it
+	typing  bool
+	code    []rune
+	runes   []rune
+	codeErr string
 }
 
 func NewGetManifestScene(game *Game) *GetManifestScene {
-	tileset := game.assets.TilesetImage
+	level := game.level
+	tileset, err := level.LoadTileset()
+	if err != nil {
+		panic(err)
+	}
 
 	// 1. Load map just to get checkpoint data
 	// We do this here so we can show the names on screen before the race starts
-
-	// m, err := tiled.LoadMapFS(embeddedAssets, "assets/nyc_1_TEST..tmj")
-	m, err := tiled.LoadMapFS(embeddedAssets, "assets/nyc_1..tmj")
+	m, err := level.LoadMap()
 	if err != nil {
 		fmt.Printf("DEBUG ERROR: Could not load map: %v\n", err)
 		panic(err)
 	}
+	spawns, err := level.Spawns(m)
+	if err != nil {
+		panic(err)
+	}
 
 	// 2. Create the data object that will persist into the race
-	// Scale is 2.0 because our RaceScene scales the map by 2
-	manifestData := NewManifest(m, game.assets.PeopleImage, 2.0)
-	fmt.Printf("DEBUG: Manifest logic complete. %d stops planned.\n", len(manifestData.Checkpoints))
+	// A shared link or command line code wins over a random seed, but only for the first race
+	seed := NewManifestSeed(level.Stage(), game.save.Difficulty)
+	if code := takeLaunchManifestCode(); code != "" {
+		if s, err := ParseManifestCode(code); err != nil {
+			fmt.Printf("DEBUG ERROR: %v\n", err)
+		} else if seedStage(s) != level.Stage() {
+			fmt.Printf("DEBUG ERROR: manifest code %s is for stage %d, not %d\n", code, seedStage(s), level.Stage())
+		} else {
+			seed = s
+		}
+	}
+	s := &GetManifestScene{
+		game:        game,
+		tileset:     tileset,
+		manifestImg: buildManifestImage(tileset, tileSize),
+		mapData:     m,
+		spawns:      spawns,
+	}
+	s.deal(seed)
+	fmt.Printf("DEBUG: Manifest logic complete. %d stops planned. Code %s\n", len(s.activeManifest.Checkpoints), s.activeManifest.Code())
+	return s
+}
+
+// deal builds the manifest for a seed on the current level
+func (s *GetManifestScene) deal(seed int64) {
+	level := s.game.level
+	_, _, paths := level.Navigation(s.mapData)
+	s.activeManifest = NewManifest(s.mapData, s.game.assets.PeopleImage, float64(level.Scale), seed, s.spawns, paths)
+	s.activeManifest.Level = level.ID
+}
+
+// loadCode swaps in the manifest for a code, or keeps the current one and says what's wrong
+func (s *GetManifestScene) loadCode(code string) bool {
+	seed, err := ParseManifestCode(code)
+	if err != nil {
+		s.codeErr = "BAD CODE, 6 LETTERS/DIGITS"
+		return false
+	}
+	if stage := seedStage(seed); stage != s.game.level.Stage() {
+		s.codeErr = fmt.Sprintf("THAT CODE IS FOR STAGE %d", stage)
+		return false
+	}
+	s.deal(seed)
+	s.codeErr = ""
+	retrotrack.PlayManifestSound()
+	return true
+}
+
+// updateDifficulty deals a fresh manifest when LEFT/RIGHT changes the difficulty
+func (s *GetManifestScene) updateDifficulty() {
+	d := s.activeManifest.Difficulty
+	if x, _ := menuFlick(s.game.input); x < 0 && d > DifficultyEasy {
+		d--
+	} else if x > 0 && d < DifficultyHard {
+		d++
+	} else {
+		return
+	}
+	s.game.save.Difficulty = d
+	s.game.persist()
+	s.deal(NewManifestSeed(s.game.level.Stage(), d))
+	s.codeErr = ""
+	retrotrack.PlayManifestSound()
+}
+
+// TakesText keeps the F/D hotkeys off while a code is being typed, both are code letters
+func (s *GetManifestScene) TakesText() bool {
+	return s.typing
+}
+
+// updateCodeEntry handles typing a code. Returns true while it has the keyboard.
+func (s *GetManifestScene) updateCodeEntry() bool {
+	in := s.game.input
+	if text := takePastedText(); text != "" {
+		if s.loadCode(text) {
+			s.typing = false
+			return true
+		}
+	}
 
-	return &GetManifestScene{
-		game:           game,
-		tileset:        tileset,
-		manifestImg:    buildManifestImage(tileset, tileSize),
-		activeManifest: manifestData,
+	if !s.typing {
+		if in.JustPressed(ActionEnterCode) {
+			s.typing = true
+			s.code = s.code[:0]
+			s.codeErr = ""
+			return true
+		}
+		return false
+	}
+
+	s.runes = ebiten.AppendInputChars(s.runes[:0])
+	for _, r := range s.runes {
+		r = unicode.ToUpper(r)
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) && len(s.code) < manifestCodeLen {
+			s.code = append(s.code, r)
+			s.codeErr = ""
+		}
 	}
+	if in.JustPressed(ActionErase) && len(s.code) > 0 {
+		s.code = s.code[:len(s.code)-1]
+	}
+	if in.JustPressed(ActionBack) {
+		s.typing = false
+	}
+	if in.JustPressed(ActionConfirm) && s.loadCode(string(s.code)) {
+		s.typing = false
+	}
+	return true
 }
 
 func (s *GetManifestScene) Update() error {
@@ -65,20 +177,16 @@ func (s *GetManifestScene) Update() error {
 		}
 	}
 
-	// Check for input to switch to the actual Race
-	startPressed := inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
-		inpututil.IsMouseButtonJustPressed(ebiten.MouseButton0)
-
-	s.touchIDs = inpututil.AppendJustPressedTouchIDs(s.touchIDs[:0])
-	if len(s.touchIDs) > 0 {
-		startPressed = true
+	if s.updateCodeEntry() {
+		return nil
 	}
+	s.updateDifficulty()
 
-	if startPressed {
+	// Check for input to switch to the actual Race
+	if s.game.input.JustPressed(ActionConfirm) || len(s.game.input.Taps()) > 0 {
 		fmt.Println("DEBUG: Switching to RaceScene. Passing manifest data...")
-		retrotrack.Start()
 		// We pass the manifest we generated so the RaceScene doesn't have to reload it
-		s.game.scene = NewRaceScene(s.game, s.activeManifest)
+		s.game.router.Follow(EventNext, s.activeManifest)
 	}
 
 	return nil
@@ -138,6 +246,20 @@ func (s *GetManifestScene) Draw(screen *ebiten.Image) {
 			ebitenutil.DebugPrintAt(screen, prefix+cp.Name, 40*zoom, yOff+(i*15))
 		}
 		ebitenutil.DebugPrintAt(screen, "PRESS SPACE TO START RACE", 10, screenHeight-20)
+
+		codeLine := "MANIFEST CODE " + s.activeManifest.Code() + "  (C) ENTER A CODE"
+		if s.typing {
+			entry := string(s.code) + strings.Repeat("_", manifestCodeLen-len(s.code))
+			codeLine = "CODE: " + entry[:3] + "-" + entry[3:] + "  (ENTER) LOAD (ESC) CANCEL"
+		}
+		if s.codeErr != "" {
+			codeLine = s.codeErr
+		}
+		ebitenutil.DebugPrintAt(screen, codeLine, 10, screenHeight-40)
+
+		m := s.activeManifest
+		diffLine := fmt.Sprintf("< %s >  PAR %s", m.Difficulty, formatRaceTime(m.Par))
+		ebitenutil.DebugPrintAt(screen, diffLine, 10, screenHeight-60)
 	}
 }
 
@@ -0,0 +1,22 @@
+package main
+
+import (
+	_ "image/png"
+	"log"
+
+	"github.com/ngolebiewski/alley_cat_1999/retrotrack"
+)
+
+// NewLevelTitleScene is the stage's title card, between the stage select and the manifest
+func NewLevelTitleScene(game *Game, level *Level) *CardScene {
+	img, err := loadImage(level.TitleImage)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return NewCardScene(game, Card{
+		Image: img,
+		Align: AlignBottom,
+		Text:  []TextBlock{{Text: level.TitleText}},
+		Sound: retrotrack.PlayCityStartSound,
+	})
+}
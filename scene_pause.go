@@ -0,0 +1,57 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// PauseScene is pushed over the race: the race keeps drawing under it but doesn't
+// update (clock included) until this is popped
+type PauseScene struct {
+	race *RaceScene
+}
+
+func NewPauseScene(race *RaceScene) *PauseScene {
+	return &PauseScene{race: race}
+}
+
+func (s *PauseScene) Update() error {
+	if s.race.game.input.JustPressed(ActionPause) {
+		s.race.game.router.Pop()
+	}
+	return nil
+}
+
+func (s *PauseScene) Draw(screen *ebiten.Image) {
+	// 1. Draw a dark semi-transparent overlay
+	vector.FillRect(screen, 0, 0, float32(screenWidth), float32(screenHeight), color.RGBA{0, 0, 0, 180}, false)
+
+	// 2. Draw the list
+	yOff := 50
+	ebitenutil.DebugPrintAt(screen, "--- ALLEY CAT RACE MANIFEST (PAUSED) ---", 40, yOff)
+	yOff += 30
+
+	if m := s.race.manifest; m != nil {
+		for i, cp := range m.Checkpoints {
+			status := "[ ] "
+			if cp.IsComplete {
+				status = "[x] "
+			}
+
+			line := status + cp.Name
+			if cp.IsFinishLine {
+				line = "[FINISH] " + cp.Name
+			}
+
+			ebitenutil.DebugPrintAt(screen, line, 50, yOff+(i*15))
+		}
+	}
+
+	ebitenutil.DebugPrintAt(screen, "\nPRESS ENTER TO RESUME", 40, screenHeight-40)
+
+	// 3. Last, over the fill and clear of the list (6px DebugPrint letters)
+	ebitenutil.DebugPrintAt(screen, "PAUSED", screenWidth/2-len("PAUSED")*6/2, 20)
+}
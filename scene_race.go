@@ -3,23 +3,47 @@ package main
 import (
 	"fmt"
 	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 
 	"github.com/ngolebiewski/alley_cat_1999/retrotrack"
 	"github.com/ngolebiewski/alley_cat_1999/tiled"
 )
 
+// Layers drawn on top of the bikers and taxis instead of under them (building tops, awnings...)
+var overheadLayers = []string{"COLLIDE-Buildings Toppers"}
+
+// The street network is read from this layer, roadGID being the plain asphalt tile
+const (
+	roadLayerName = "Roads and Sidewalks"
+	roadGID       = 2
+)
+
+// buildNavigation sets up the collision grid (queried in world pixels), the street
+// graph (nil if the map has no road layer) and the A* pathfinder over both
+func buildNavigation(m *tiled.Map, scale float64) (*tiled.CollisionGrid, *tiled.RoadGraph, *tiled.Pathfinder) {
+	var roads *tiled.RoadGraph
+	if layer := m.FindTileLayer(roadLayerName); layer != nil {
+		roads = tiled.BuildRoadGraph(m, layer, func(gid uint32) bool { return gid == roadGID })
+	}
+	grid := tiled.BuildCollisionGrid(m)
+	grid.Scale = scale
+	return grid, roads, tiled.NewPathfinder(grid, roads)
+}
+
 type RaceScene struct {
 	game   *Game
 	hud    *HUDOverlay
 	player *Player
 	camera *Camera
-	paused bool
-	stick  joystick // used by input.go for mobile/touch screen devices only its a virtual joystick!
+	clock  *RaceClock // race time, ticks only while the race runs
 
+	// World rectangle in world pixels, worldX/worldY are only non-zero for infinite maps
+	worldX float64
+	worldY float64
 	worldW float64
 	worldH float64
 
@@ -27,12 +51,18 @@ type RaceScene struct {
 	mapDraw *tiled.Renderer
 	collide *tiled.CollisionGrid
 
-	// Mission Data
-	manifest *Manifest
+	// Trigger zones drawn in Tiled (park shortcuts, construction...), tracked for the player
+	triggers *tiled.Triggers
 
-	// Fade-in & Fade-out stuff
-	fader     *Fader
-	isExiting bool
+	// Intersections and street segments, shared by taxis, rivals and anything else that drives
+	roads *tiled.RoadGraph
+	// A* routes for the rivals, over the collision grid
+	paths *tiled.Pathfinder
+
+	// Mission Data
+	manifest         *Manifest
+	playerFinishTime float64 // race clock seconds when the player crossed the finish line
+	bestSplits       []Split // the best run before this one, set at the finish for the end screen
 
 	// CPU entities + Collision System
 	taxiManager  *TaxiManager
@@ -41,35 +71,54 @@ type RaceScene struct {
 }
 
 func NewRaceScene(game *Game, mfest *Manifest) *RaceScene {
-	// m, err := tiled.LoadMapFS(embeddedAssets, "assets/nyc_1_TEST..tmj")
-	m, err := tiled.LoadMapFS(embeddedAssets, "assets/nyc_1..tmj")
+	level := game.level
+	m, err := level.LoadMap()
+	if err != nil {
+		panic(err)
+	}
+	sprites, err := level.LoadTileset()
 	if err != nil {
 		panic(err)
 	}
 
-	scale := 2
+	spawns, err := level.Spawns(m)
+	if err != nil {
+		panic(err)
+	}
+
+	scale := level.Scale
+	startX, startY := spawns.PlayerStart.X*float64(scale), spawns.PlayerStart.Y*float64(scale)
+
+	// One image per tileset in the map (right now just NEW_nyc_spritesheet-Recovered.png)
+	tilesetImages, err := loadTilesetImages(m)
+	if err != nil {
+		panic(err)
+	}
 
 	renderer := tiled.NewRenderer(
 		m,
-		game.assets.TilesetImage, // nyc_tileset.png
-		float64(scale),           // 16px → 32px
+		tilesetImages,
+		float64(scale), // 16px → 32px
 	)
 
+	clock := &RaceClock{}
 	scene := &RaceScene{
 		game:         game,
-		hud:          NewHUDOverlay(),
-		player:       NewPlayer(game.assets.BikerImage, 50, 1630, 32, 32), // player:       NewPlayer(game.assets.BikerImage, 50, 400, 32, 32), // good for the TEST tilemap
+		clock:        clock,
+		hud:          NewHUDOverlay(clock),
+		player:       NewPlayer(game.assets.BikerImage, startX, startY, 32, 32),
 		mapData:      m,
 		mapDraw:      renderer,
-		fader:        NewFader(0, 0.5), // <--- Start at 1.0 (fully black)
 		collisionSys: &CollisionSystem{game: game},
 		manifest:     mfest,
 	}
 
-	scene.npcManager = NewNPCManager(160, 420, scene)
+	scene.npcManager = NewNPCManager(spawns.RivalStart.X*float64(scale), spawns.RivalStart.Y*float64(scale), scene, mfest.Rand())
 
-	worldW := m.Width * m.TileWidth * scale
-	worldH := m.Height * m.TileHeight * scale
+	// The map's real bounds, infinite maps can grow in any direction
+	bounds := m.PixelBounds()
+	worldW := bounds.Dx() * scale
+	worldH := bounds.Dy() * scale
 
 	scene.camera = NewCamera(
 		screenWidth,
@@ -77,13 +126,32 @@ func NewRaceScene(game *Game, mfest *Manifest) *RaceScene {
 		worldW,
 		worldH,
 	)
+	scene.camera.WorldX = bounds.Min.X * scale
+	scene.camera.WorldY = bounds.Min.Y * scale
 
+	scene.worldX = float64(bounds.Min.X * scale)
+	scene.worldY = float64(bounds.Min.Y * scale)
 	scene.worldW = float64(worldW)
 	scene.worldH = float64(worldH)
 	// scene.taxiManager.worldW = scene.worldW
 	// scene.taxiManager.worldH = scene.worldH
-	scene.taxiManager = NewTaxiManager(game.assets.TilesetImage, 2.0, scene.worldW, scene.worldH, m) // scale 2x
-	scene.collide = tiled.BuildCollisionGrid(m)
+	scene.taxiManager = NewTaxiManager(sprites, float64(scale), scene.worldW, scene.worldH, m, level.TaxiDensity)
+	scene.taxiManager.worldX = scene.worldX
+	scene.taxiManager.worldY = scene.worldY
+	scene.collide, scene.roads, scene.paths = level.Navigation(m)
+	scene.taxiManager.roads = scene.roads
+	scene.triggers = tiled.NewTriggers(m)
+	scene.triggers.Scale = float64(scale)
+	scene.triggers.OnEnter("", func(z *tiled.Zone) {
+		if isDebugMode {
+			fmt.Printf("DEBUG: entered zone %s\n", z.Name)
+		}
+	})
+	scene.triggers.OnExit("", func(z *tiled.Zone) {
+		if isDebugMode {
+			fmt.Printf("DEBUG: left zone %s\n", z.Name)
+		}
+	})
 	scene.hud.maxCheck = len(mfest.Checkpoints) //sets the number of checkpoints on the HUD
 
 	return scene
@@ -93,72 +161,85 @@ func (s *RaceScene) clampPlayer() {
 	pw := s.player.w
 	ph := s.player.h
 
-	if s.player.x < 0 {
-		s.player.x = 0
+	if s.player.x < s.worldX {
+		s.player.x = s.worldX
 	}
-	if s.player.y < 0 {
-		s.player.y = 0
+	if s.player.y < s.worldY {
+		s.player.y = s.worldY
 	}
 
-	if s.player.x+pw > s.worldW {
-		s.player.x = s.worldW - pw
+	if s.player.x+pw > s.worldX+s.worldW {
+		s.player.x = s.worldX + s.worldW - pw
 	}
-	if s.player.y+ph > s.worldH {
-		s.player.y = s.worldH - ph
+	if s.player.y+ph > s.worldY+s.worldH {
+		s.player.y = s.worldY + s.worldH - ph
 	}
 }
 
-func (s *RaceScene) Update() error {
-	// 1. Update the Fader first (handles alpha timing)
-	s.fader.Update()
-
-	// 2. Scene Transition Logic
-	// If we are fading out and the fader hit 1.0 alpha, swap the scene
-	if s.isExiting && s.fader.Finished {
-		retrotrack.Stop()
-		s.game.scene = NewEndScene(s.game, s.hud.elapsedTimeStr(), s.player.cash)
-		return nil
-	}
+// Enter starts the stage's music, from the top on every restart
+func (s *RaceScene) Enter() {
+	retrotrack.StartSong(s.game.level.Music)
+}
 
-	// 3. Handle Exit Trigger
-	// Start the fade out process when ESC is pressed
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && !s.isExiting {
-		s.isExiting = true
-		s.fader = NewFader(FadeOut, 0.25) // 0.5 seconds to black
-	}
+// Exit stops the music whichever way the race ended
+func (s *RaceScene) Exit() {
+	retrotrack.Stop()
+}
 
-	// 4. Pause Logic
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || s.isButtonPressed("START") {
-		s.paused = !s.paused
+// leave banks the cash and hands the race over to the end screen.
+// The router fades out from here and stops updating us, so the world freezes as is.
+func (s *RaceScene) leave(event string) {
+	s.game.save.TotalCash += s.player.cash
+	s.game.persist()
+	s.game.router.Follow(event, endArgs{
+		cash:     s.player.cash,
+		results:  s.finalResults(),
+		splits:   splitBreakdown(s.clock.Splits(), s.bestSplits),
+		manifest: s.manifest,
+	})
+}
+
+func (s *RaceScene) Update() error {
+	in := s.game.input
+
+	// 1. Handle Exit Trigger
+	if in.JustPressed(ActionBack) {
+		s.leave(EventQuit)
+		return nil
 	}
 
-	// 5. Early Return
-	// Stop world updates if the game is paused or we are currently fading out
-	if s.paused || s.isExiting {
+	// 2. Pause Logic, the overlay takes the input until it's unpaused
+	if in.JustPressed(ActionPause) {
+		s.game.router.Push(NewPauseScene(s))
 		return nil
 	}
 
-	// 6. Gather Input
+	// The race clock only runs from here on, and tile animations (signs, steam,
+	// harbor water) follow it so they freeze with the pause too
+	s.clock.Tick()
+	s.mapDraw.Clock = s.clock.Elapsed()
+
+	// 3. Gather Input
 	var inX, inY float64
-	jx, jy := s.getJoystickVector()
+	jx, jy := in.Steer()
 
-	// Keyboard or Virtual Joystick
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) || jx < -0.2 {
+	// Keyboard, gamepad or Virtual Joystick, all the same to the bike
+	if jx < -steerDeadzone {
 		inX = -1
-	} else if ebiten.IsKeyPressed(ebiten.KeyRight) || jx > 0.2 {
+	} else if jx > steerDeadzone {
 		inX = 1
 	}
 
-	if ebiten.IsKeyPressed(ebiten.KeyUp) || jy < -0.2 {
+	if jy < -steerDeadzone {
 		inY = -1
-	} else if ebiten.IsKeyPressed(ebiten.KeyDown) || jy > 0.2 {
+	} else if jy > steerDeadzone {
 		inY = 1
 	}
 
-	toggleAxis := inpututil.IsKeyJustPressed(ebiten.KeySpace) || s.isButtonJustPressed("A")
-	toggleMount := inpututil.IsKeyJustPressed(ebiten.KeyB) || s.isButtonJustPressed("B")
+	toggleAxis := in.JustPressed(ActionToggleAxis)
+	toggleMount := in.JustPressed(ActionMount)
 
-	// 7. Physics & Movement (The Order Matters!)
+	// 4. Physics & Movement (The Order Matters!)
 
 	// A. Calculate Player Velocity/Animation based on input
 	s.player.UpdateInput(inX, inY, toggleAxis, toggleMount)
@@ -166,15 +247,15 @@ func (s *RaceScene) Update() error {
 	// B. Move player and resolve Tiled map collisions (walls)
 	s.movePlayerWithCollisionGrid()
 	s.clampPlayer()
+	s.triggers.Update(s.player.x, s.player.y, s.player.w, s.player.h)
 
 	// C. Update Taxis (Movement & internal timers)
 	s.taxiManager.Update(s.player.x, s.player.y)
 
 	// NEW: Update NPCs
 	if s.npcManager != nil {
-		// Pass manifest for AI targets and HUD time for their finish times
-		// s.npcManager.Update(s.manifest, s.taxiManager.taxis, s, s.hud.timer.Seconds())
-		s.npcManager.Update(s.manifest, s.taxiManager.taxis, s, s.collide, 666.0)
+		// Pass manifest for AI targets and the race clock for their finish times
+		s.npcManager.Update(s.manifest, s.taxiManager.taxis, s, s.collide, s.clock.Seconds())
 	}
 
 	// D. Resolve Entity Collisions (Player vs Taxis, Taxi vs Taxi)
@@ -184,14 +265,10 @@ func (s *RaceScene) Update() error {
 	s.hud.health = float32(s.player.health) / 100.0
 	s.hud.cash = s.player.cash
 
-	// 8. Camera & UI
+	// 5. Camera & UI
 	px, py := s.player.Center()
 	s.camera.Follow(px, py)
 
-	if isMobile {
-		s.updateJoystick()
-	}
-
 	// CHECKPOINTS STUFF
 	// --- CHECKPOINT LOGIC ---
 	if s.manifest != nil {
@@ -214,7 +291,8 @@ func (s *RaceScene) Update() error {
 				dx, dy := px-cp.X, py-cp.Y
 				if (dx*dx + dy*dy) < 32*32 {
 					cp.IsComplete = true
-					s.player.cash += 100
+					s.checkIn(cp)
+					s.player.cash += s.manifest.Payout
 					retrotrack.PlayManifestSound()
 
 					// --- UPDATE HUD HERE ---
@@ -232,21 +310,25 @@ func (s *RaceScene) Update() error {
 					dx, dy := px-cp.X, py-cp.Y
 					if (dx*dx + dy*dy) < 32*32 {
 						cp.IsComplete = true
+						s.playerFinishTime = s.checkIn(cp).Seconds()
+						s.bestSplits = s.game.save.RecordRun(s.manifest.Key(), s.clock.Splits())
+						s.game.save.Cleared[s.game.level.ID] = true // unlocks the next stage
 
-						// Start the Exit Transition to End Scene
-						s.isExiting = true
-						s.fader = NewFader(FadeOut, 0.25)
+						// Fade out to the End Scene, leave saves all of the above
+						s.leave(EventFinish)
+						return nil
 					}
 				}
 			}
 		}
 	}
 
-	// GAME OVER
-	if s.player.state == StateHospital && !s.isExiting {
-		s.isExiting = true
-		// Small delay or fader before switching
-		s.game.scene = NewGameOverScene(s.game, s.manifest)
+	s.hud.position, s.hud.riders = playerPosition(s.standings())
+
+	// GAME OVER. The cash made on this run is forfeit on purpose, not banked like in
+	// leave: the retry resets the check-ins and pays for the same stops again.
+	if s.player.state == StateHospital {
+		s.game.router.Follow(EventCrash, s.manifest)
 		return nil
 	}
 
@@ -257,8 +339,8 @@ func (s *RaceScene) Draw(screen *ebiten.Image) {
 	screen.Fill(color.RGBA{20, 20, 20, 255})
 	ebitenutil.DebugPrintAt(screen, "Press ESC to exit | 'F' for Full Screen\n'SPACE' to flip vert/horiz | 'B' to get on/off bike", 0, screenHeight-30)
 
-	// MAP FIRST
-	s.mapDraw.Draw(screen, s.camera.X, s.camera.Y)
+	// MAP FIRST (ground only, overhead layers go on after the entities)
+	s.mapDraw.DrawLayersExcept(screen, s.camera.X, s.camera.Y, overheadLayers...)
 
 	// 2. CHECKPOINTS / CLIENTS
 	if s.manifest != nil {
@@ -266,16 +348,12 @@ func (s *RaceScene) Draw(screen *ebiten.Image) {
 			cp.Draw(screen, s.camera) // This uses the Draw method in manifest.go
 		}
 	}
-	// 3. RIVAL NPC BIKERS
-	// // Removing because the AI they run on is really ANNOYING, and decreases the fun at the moment.
-	// // A simpler approach could be good. Like setting up a node network/graph...not happening this late in the game jam!
-	// so commenting out for now. Even though it will still run in the background. Hopefully it will all still compile -Nick
-
-	// if s.npcManager != nil {
-	// 	// We use the player's biker image as the base,
-	// 	// the NPC Draw function handles the color tinting.
-	// 	s.npcManager.Draw(screen, s.camera, s.game.assets.BikerImage)
-	// }
+	// 3. RIVAL NPC BIKERS (they follow A* routes now, see NPCBiker.followRoute)
+	if s.npcManager != nil {
+		// We use the player's biker image as the base,
+		// the NPC Draw function handles the color tinting.
+		s.npcManager.Draw(screen, s.camera, s.game.assets.BikerImage)
+	}
 
 	//ENTITIES
 	// s.player.Draw(screen) // this was the non camera way to draw
@@ -283,6 +361,11 @@ func (s *RaceScene) Draw(screen *ebiten.Image) {
 
 	s.taxiManager.Draw(screen, s.camera)
 
+	// OVERHEAD MAP LAYERS
+	for _, name := range overheadLayers {
+		s.mapDraw.DrawLayer(screen, name, s.camera.X, s.camera.Y)
+	}
+
 	s.hud.Draw(screen)
 
 	if isDebugMode {
@@ -292,13 +375,6 @@ func (s *RaceScene) Draw(screen *ebiten.Image) {
 	if isMobile {
 		s.drawMobileUI(screen)
 	}
-	if s.paused {
-		ebitenutil.DebugPrintAt(screen, "PAUSED", 140, 110)
-		s.drawPauseOverlay(screen)
-	}
-
-	// 4. DRAW FADER LAST
-	s.fader.Draw(screen)
 }
 
 func (s *RaceScene) movePlayerWithCollisionGrid() {
@@ -344,59 +420,87 @@ func (s *RaceScene) movePlayerWithCollisionGrid() {
 
 // Check collision using CollisionGrid
 func (s *RaceScene) collidesAt(px, py float64) bool {
-	const tileSize = 32
-
 	grid := s.collide
 	if grid == nil {
 		return false
 	}
-
-	// Convert player rectangle into tile coordinates
-	x1 := int(px) / tileSize
-	y1 := int(py) / tileSize
-	x2 := int(px+s.player.w-1) / tileSize
-	y2 := int(py+s.player.h-1) / tileSize
-
-	for y := y1; y <= y2; y++ {
-		for x := x1; x <= x2; x++ {
-			if y < 0 || y >= grid.Height || x < 0 || x >= grid.Width {
-				continue
-			}
-			if grid.Solid[y][x] {
-				return true
-			}
-		}
-	}
-
-	return false
+	return grid.RectCollides(px, py, s.player.w, s.player.h)
 }
 
 func (s *RaceScene) drawCollisionDebug(screen *ebiten.Image) {
-	const tile = 32
+	tw, th := float64(s.collide.TileWidth)*s.collide.Scale, float64(s.collide.TileHeight)*s.collide.Scale
 	for y := 0; y < s.collide.Height; y++ {
 		for x := 0; x < s.collide.Width; x++ {
 			if s.collide.Solid[y][x] {
 				ebitenutil.DrawRect(
 					screen,
-					float64(x*tile)-s.camera.X,
-					float64(y*tile)-s.camera.Y,
-					tile,
-					tile,
+					float64(x+s.collide.OriginX)*tw-s.camera.X,
+					float64(y+s.collide.OriginY)*th-s.camera.Y,
+					tw,
+					th,
 					color.RGBA{255, 0, 0, 80},
 				)
 			}
 		}
 	}
+
+	// Partial colliders from the tileset's collision editor and object layers
+	for _, shape := range s.collide.Shapes {
+		s.drawShapeDebug(screen, shape, s.collide.Scale, color.RGBA{255, 128, 0, 200})
+	}
+
+	// Trigger zones
+	for _, z := range s.triggers.Zones {
+		s.drawShapeDebug(screen, z.Shape, s.triggers.Scale, color.RGBA{0, 200, 255, 200})
+	}
+
+	s.drawRoadGraphDebug(screen)
 }
 
-// Returns the Tile GID at a specific world coordinate for a specific layer name
-func (s *RaceScene) getTileIDAt(worldX, worldY float64, layerName string) int {
-	tx, ty := int(worldX/32), int(worldY/32)
+// drawRoadGraphDebug shows the street centerlines and intersections
+func (s *RaceScene) drawRoadGraphDebug(screen *ebiten.Image) {
+	if s.roads == nil {
+		return
+	}
+	scale := s.collide.Scale
+	tw, th := float64(s.roads.TileWidth)*scale, float64(s.roads.TileHeight)*scale
 
-	// Boundary Check
-	if tx < 0 || tx >= s.mapData.Width || ty < 0 || ty >= s.mapData.Height {
-		return 0
+	for _, e := range s.roads.Edges {
+		clr := color.RGBA{0, 255, 0, 160}
+		if e.OneWay {
+			clr = color.RGBA{255, 255, 0, 160}
+		}
+		for i := 1; i < len(e.Path); i++ {
+			a, b := e.Path[i-1], e.Path[i]
+			vector.StrokeLine(screen,
+				float32((float64(a.X)+0.5)*tw-s.camera.X), float32((float64(a.Y)+0.5)*th-s.camera.Y),
+				float32((float64(b.X)+0.5)*tw-s.camera.X), float32((float64(b.Y)+0.5)*th-s.camera.Y),
+				2, clr, false,
+			)
+		}
+	}
+	for i := range s.roads.Nodes {
+		x, y := s.roads.NodePos(i)
+		vector.DrawFilledCircle(screen, float32(x*scale-s.camera.X), float32(y*scale-s.camera.Y), 5, color.RGBA{0, 255, 0, 220}, false)
 	}
+}
+
+func (s *RaceScene) drawShapeDebug(screen *ebiten.Image, shape tiled.Shape, scale float64, clr color.Color) {
+	n := len(shape.Points)
+	for i := range shape.Points {
+		a, b := shape.Points[i], shape.Points[(i+1)%n]
+		vector.StrokeLine(screen,
+			float32(a.X*scale-s.camera.X), float32(a.Y*scale-s.camera.Y),
+			float32(b.X*scale-s.camera.X), float32(b.Y*scale-s.camera.Y),
+			1, clr, false,
+		)
+	}
+}
+
+// Returns the Tile GID at a specific world coordinate for a specific layer name
+func (s *RaceScene) getTileIDAt(worldX, worldY float64, layerName string) int {
+	tw, th := float64(s.collide.TileWidth)*s.collide.Scale, float64(s.collide.TileHeight)*s.collide.Scale
+	tx, ty := int(math.Floor(worldX/tw)), int(math.Floor(worldY/th))
 
 	// Use the existing recursive function to find the layer
 	layer := findLayerRecursive(s.mapData.Layers, layerName)
@@ -404,13 +508,8 @@ func (s *RaceScene) getTileIDAt(worldX, worldY float64, layerName string) int {
 		return 0
 	}
 
-	idx := ty*s.mapData.Width + tx
-	if idx >= 0 && idx < len(layer.Data) {
-		// FIX: Cast the uint32 value to int to match function signature
-		return int(layer.Data[idx])
-	}
-
-	return 0
+	// TileAt does the boundary check (and reads infinite maps too)
+	return int(s.mapData.TileAt(layer, tx, ty))
 }
 
 // Checks if the tile at Layer 3 (Blocked) has a non-zero GID
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/ngolebiewski/alley_cat_1999/retrotrack"
+)
+
+const (
+	stageListY   = 50 // first row of the list
+	stageRowStep = 20
+)
+
+// StageSelectScene lists the campaign (see stages). Finishing a stage unlocks the next one.
+type StageSelectScene struct {
+	game   *Game
+	cursor int
+}
+
+func NewStageSelectScene(game *Game) *StageSelectScene {
+	return &StageSelectScene{game: game, cursor: max(slices.Index(stages(), game.level), 0)}
+}
+
+func (s *StageSelectScene) Update() error {
+	in := s.game.input
+	list := stages()
+	s.cursor = min(s.cursor, len(list)-1) // debug mode can be switched off under us
+	if _, y := menuFlick(in); y < 0 && s.cursor > 0 {
+		s.cursor--
+	} else if y > 0 && s.cursor < len(list)-1 {
+		s.cursor++
+	}
+
+	pick := in.JustPressed(ActionConfirm)
+
+	// Click or tap a row to pick it
+	for _, t := range in.Taps() {
+		if row := (t.Y - stageListY) / stageRowStep; t.Y >= stageListY && row < len(list) {
+			s.cursor = row
+			pick = true
+		}
+	}
+
+	if pick {
+		level := list[s.cursor]
+		if !s.game.save.Unlocked(level) {
+			retrotrack.PlayHonk()
+			return nil
+		}
+		s.game.level = level
+		s.game.save.Level = level.ID
+		s.game.persist()
+		retrotrack.PlayCityStartSound()
+		s.game.router.Follow(EventNext, level)
+	}
+	return nil
+}
+
+func (s *StageSelectScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{0, 0, 0, 255})
+	ebitenutil.DebugPrint(screen, "--- PICK A STAGE ---\nUP/DOWN + ENTER, or tap one")
+
+	for i, l := range stages() {
+		cursor := "  "
+		if i == s.cursor {
+			cursor = "> "
+		}
+		status := ""
+		switch {
+		case !s.game.save.Unlocked(l):
+			status = "LOCKED"
+		case s.game.save.Cleared[l.ID]:
+			status = "CLEARED"
+		}
+		line := fmt.Sprintf("%sSTAGE %d  %-18s %s", cursor, l.Stage(), l.Name, status)
+		ebitenutil.DebugPrintAt(screen, line, 20, stageListY+i*stageRowStep)
+	}
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("BANK $%d", s.game.save.TotalCash), 10, screenHeight-20)
+}
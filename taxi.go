@@ -104,22 +104,18 @@ func NewTaxi(manager *TaxiManager, frames []*ebiten.Image, x, y, speed float64,
 }
 
 func (t *Taxi) canDriveAt(px, py float64) bool {
+	if t.manager != nil && t.manager.roads != nil {
+		return t.manager.roads.IsRoadAt(px/t.scale, py/t.scale)
+	}
 	if t.manager == nil || t.manager.roadLayer == nil || t.manager.spawnMap == nil {
 		return false
 	}
 	m := t.manager.spawnMap
 	gridSize := 16.0 * t.scale
-	tileX := int(px / gridSize)
-	tileY := int(py / gridSize)
+	tileX := int(math.Floor(px / gridSize))
+	tileY := int(math.Floor(py / gridSize))
 
-	if tileX < 0 || tileY < 0 || tileX >= m.Width || tileY >= m.Height {
-		return false
-	}
-	idx := tileY*m.Width + tileX
-	if idx < 0 || idx >= len(t.manager.roadLayer.Data) {
-		return false
-	}
-	return t.manager.roadLayer.Data[idx] == 2
+	return m.TileAt(t.manager.roadLayer, tileX, tileY) == 2
 }
 
 func (t *Taxi) Update(playerX, playerY float64) {
@@ -233,17 +229,18 @@ func (t *Taxi) isPlayerInFront(px, py float64) bool {
 }
 
 func (t *Taxi) isOutOfBounds() bool {
-	limitW := t.manager.worldW
-	limitH := t.manager.worldH
+	minX, minY := t.manager.worldX, t.manager.worldY
+	limitW := minX + t.manager.worldW
+	limitH := minY + t.manager.worldH
 	buffer := 120.0 * t.scale
 
 	switch t.dir {
 	case "RIGHT":
 		return t.x > limitW+buffer
 	case "LEFT":
-		return t.x < -buffer
+		return t.x < minX-buffer
 	case "UP":
-		return t.y < -buffer
+		return t.y < minY-buffer
 	case "DOWN":
 		return t.y > limitH+buffer
 	}
@@ -316,16 +313,16 @@ func (t *Taxi) Respawn() {
 
 	switch t.dir {
 	case "RIGHT":
-		t.x = -buffer
+		t.x = t.manager.worldX - buffer
 		t.y = t.laneY
 	case "LEFT":
-		t.x = t.manager.worldW + buffer
+		t.x = t.manager.worldX + t.manager.worldW + buffer
 		t.y = t.laneY
 	case "UP":
-		t.y = t.manager.worldH + buffer
+		t.y = t.manager.worldY + t.manager.worldH + buffer
 		t.x = t.laneX
 	case "DOWN":
-		t.y = -buffer
+		t.y = t.manager.worldY - buffer
 		t.x = t.laneX
 	}
 
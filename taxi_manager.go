@@ -19,15 +19,19 @@ type TaxiSpawn struct {
 type TaxiManager struct {
 	taxis     []*Taxi
 	scale     float64
+	worldX    float64 // top-left of the world, only non-zero for infinite maps
+	worldY    float64
 	worldW    float64
 	worldH    float64
-	particles *ParticleSystem // for crash effects
-	spawnMap  *tiled.Map      // Pointer to the full map data
-	roadLayer *tiled.Layer    // Cached reference to the specific road layer
+	particles *ParticleSystem  // for crash effects
+	spawnMap  *tiled.Map       // Pointer to the full map data
+	roadLayer *tiled.Layer     // Cached reference to the specific road layer
+	roads     *tiled.RoadGraph // set by the race scene, nil means fall back to roadLayer
 }
 
-// NewTaxiManager initializes taxis and sets world size
-func NewTaxiManager(tileset *ebiten.Image, scale float64, worldW, worldH float64, spawnMap *tiled.Map) *TaxiManager {
+// NewTaxiManager initializes taxis and sets world size.
+// density is the share of the map's taxi spawns that get a taxi (1 = all of them).
+func NewTaxiManager(tileset *ebiten.Image, scale float64, worldW, worldH float64, spawnMap *tiled.Map, density float64) *TaxiManager {
 	tm := &TaxiManager{
 		scale:    scale,
 		worldW:   worldW,
@@ -82,7 +86,12 @@ func NewTaxiManager(tileset *ebiten.Image, scale float64, worldW, worldH float64
 
 	// Spawn Taxis
 	tiledSpawns := tiled.ExtractTaxiSpawns(spawnMap)
-	for _, s := range tiledSpawns {
+	for i, s := range tiledSpawns {
+		// Spread the skipped ones evenly instead of dropping the end of the list
+		if int(float64(i+1)*density) == int(float64(i)*density) {
+			continue
+		}
+
 		var frames []*ebiten.Image
 		if s.Direction == "UP" || s.Direction == "DOWN" {
 			frames = upFrames
@@ -153,7 +162,7 @@ func (tm *TaxiManager) Draw(screen *ebiten.Image, cam *Camera) {
 
 func findLayerRecursive(layers []tiled.Layer, name string) *tiled.Layer {
 	for i := range layers {
-		if layers[i].Name == name && layers[i].Type == "tilelayer" && len(layers[i].Data) > 0 {
+		if layers[i].Name == name && layers[i].Type == "tilelayer" && (len(layers[i].Data) > 0 || len(layers[i].Chunks) > 0) {
 			return &layers[i]
 		}
 		if layers[i].Type == "group" && len(layers[i].Layers) > 0 {
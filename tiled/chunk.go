@@ -0,0 +1,212 @@
+package tiled
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ChunkSize is how many tiles wide and tall one baked chunk is.
+// 16 tiles of 16px = a 256px offscreen image per chunk (before Scale).
+const ChunkSize = 16
+
+// layerCache holds the baked chunks of one tile layer.
+// Chunk (ox, oy) is the top-left one, it's below zero when an infinite map grows up or left.
+type layerCache struct {
+	ox, oy     int
+	cols, rows int
+	chunks     []chunk
+}
+
+// at returns the chunk at chunk coordinates (cx, cy), or nil outside the map
+func (lc *layerCache) at(cx, cy int) *chunk {
+	x, y := cx-lc.ox, cy-lc.oy
+	if x < 0 || y < 0 || x >= lc.cols || y >= lc.rows {
+		return nil
+	}
+	return &lc.chunks[y*lc.cols+x]
+}
+
+type chunk struct {
+	img      *ebiten.Image // nil when the chunk has no static tiles at all
+	baked    bool
+	animated []animatedCell // drawn every frame on top of img, never baked
+}
+
+type animatedCell struct {
+	x, y int
+	raw  uint32
+	def  *TileDef
+}
+
+func (r *Renderer) cacheFor(layer *Layer) *layerCache {
+	if r.cache == nil {
+		r.cache = map[*Layer]*layerCache{}
+	}
+
+	lc, ok := r.cache[layer]
+	if !ok {
+		b := r.Map.Bounds()
+		ox, oy := floorDiv(b.Min.X, ChunkSize), floorDiv(b.Min.Y, ChunkSize)
+		cols, rows := 0, 0
+		if !b.Empty() {
+			cols = floorDiv(b.Max.X-1, ChunkSize) - ox + 1
+			rows = floorDiv(b.Max.Y-1, ChunkSize) - oy + 1
+		}
+		lc = &layerCache{
+			ox:     ox,
+			oy:     oy,
+			cols:   cols,
+			rows:   rows,
+			chunks: make([]chunk, cols*rows),
+		}
+		r.cache[layer] = lc
+	}
+	return lc
+}
+
+// overflow is how far the biggest tileset tiles stick out of a map cell
+// (up and to the right), so chunk images leave room for them.
+func (r *Renderer) overflow() (int, int) {
+	padX, padY := 0, 0
+	for _, ts := range r.Map.Tilesets {
+		padX = max(padX, ts.TileWidth-r.Map.TileWidth)
+		padY = max(padY, ts.TileHeight-r.Map.TileHeight)
+	}
+	return padX, padY
+}
+
+// drawChunks draws the chunks of a layer that intersect the screen, baking them first if needed
+func (r *Renderer) drawChunks(screen *ebiten.Image, layer *Layer, camX, camY float64) {
+	lc := r.cacheFor(layer)
+	padX, padY := r.overflow()
+
+	cw := float64(ChunkSize * r.Map.TileWidth)
+	ch := float64(ChunkSize * r.Map.TileHeight)
+
+	// Viewport in unscaled map pixels
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	x0, y0 := camX/r.Scale, camY/r.Scale
+	x1, y1 := (camX+float64(sw))/r.Scale, (camY+float64(sh))/r.Scale
+
+	minCX := max(lc.ox, int(math.Floor((x0-float64(padX))/cw)))
+	maxCX := min(lc.ox+lc.cols-1, int(math.Floor(x1/cw)))
+	minCY := max(lc.oy, int(math.Floor(y0/ch)))
+	maxCY := min(lc.oy+lc.rows-1, int(math.Floor((y1+float64(padY))/ch)))
+
+	for cy := minCY; cy <= maxCY; cy++ {
+		for cx := minCX; cx <= maxCX; cx++ {
+			c := lc.at(cx, cy)
+			if !c.baked {
+				r.bakeChunk(layer, c, cx, cy)
+			}
+
+			if c.img != nil {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(cx)*cw, float64(cy)*ch-float64(padY))
+				op.GeoM.Scale(r.Scale, r.Scale)
+				op.GeoM.Translate(-camX, -camY)
+				screen.DrawImage(c.img, op)
+			}
+
+			if len(c.animated) > 0 {
+				var geo ebiten.GeoM
+				geo.Scale(r.Scale, r.Scale)
+				geo.Translate(-camX, -camY)
+				for _, a := range c.animated {
+					r.drawTile(screen, r.animatedGID(a.raw, a.def), a.x, a.y, geo)
+				}
+			}
+		}
+	}
+}
+
+// bakeChunk renders every tile of one chunk into its offscreen image
+func (r *Renderer) bakeChunk(layer *Layer, c *chunk, cx, cy int) {
+	c.baked = true
+	c.animated = c.animated[:0]
+	padX, padY := r.overflow()
+
+	b := r.Map.Bounds()
+	tx0, ty0 := cx*ChunkSize, cy*ChunkSize
+	tx1 := min(tx0+ChunkSize, b.Max.X)
+	ty1 := min(ty0+ChunkSize, b.Max.Y)
+	tx0, ty0 = max(tx0, b.Min.X), max(ty0, b.Min.Y)
+
+	// Animated tiles are pulled out here, they can't live in a baked image
+	empty := true
+	for ty := ty0; ty < ty1; ty++ {
+		for tx := tx0; tx < tx1; tx++ {
+			raw := r.Map.TileAt(layer, tx, ty)
+			if raw == 0 {
+				continue
+			}
+			if td := r.animation(raw); td != nil {
+				c.animated = append(c.animated, animatedCell{x: tx, y: ty, raw: raw, def: td})
+				continue
+			}
+			empty = false
+		}
+	}
+
+	if empty {
+		if c.img != nil {
+			c.img.Deallocate()
+			c.img = nil
+		}
+		return
+	}
+
+	if c.img == nil {
+		c.img = ebiten.NewImage(
+			ChunkSize*r.Map.TileWidth+padX,
+			ChunkSize*r.Map.TileHeight+padY,
+		)
+	} else {
+		c.img.Clear()
+	}
+
+	// Chunk-local coordinates: top-left tile of the chunk sits at (0, padY)
+	var geo ebiten.GeoM
+	geo.Translate(-float64(cx*ChunkSize*r.Map.TileWidth), -float64(cy*ChunkSize*r.Map.TileHeight)+float64(padY))
+
+	for ty := ty0; ty < ty1; ty++ {
+		for tx := tx0; tx < tx1; tx++ {
+			if raw := r.Map.TileAt(layer, tx, ty); raw != 0 && r.animation(raw) == nil {
+				r.drawTile(c.img, raw, tx, ty, geo)
+			}
+		}
+	}
+}
+
+// SetTile changes one tile of a named tile layer and re-bakes only the chunk it lives in.
+// Returns false if the layer or the cell doesn't exist.
+func (r *Renderer) SetTile(layerName string, tx, ty int, gid uint32) bool {
+	layer := r.Map.FindTileLayer(layerName)
+	if layer == nil {
+		return false
+	}
+	if !r.Map.setTileAt(layer, tx, ty, gid) {
+		return false
+	}
+
+	if lc, ok := r.cache[layer]; ok {
+		if c := lc.at(floorDiv(tx, ChunkSize), floorDiv(ty, ChunkSize)); c != nil {
+			c.baked = false
+		}
+	}
+	return true
+}
+
+// Invalidate throws away every baked chunk, e.g. after swapping Images or editing Map by hand
+func (r *Renderer) Invalidate() {
+	r.anims = nil
+	for _, lc := range r.cache {
+		for i := range lc.chunks {
+			if lc.chunks[i].img != nil {
+				lc.chunks[i].img.Deallocate()
+			}
+		}
+	}
+	r.cache = nil
+}
@@ -0,0 +1,67 @@
+package tiled
+
+import "testing"
+
+func TestFindLayer(t *testing.T) {
+	m := &Map{Layers: []Layer{
+		{Name: "Ground", Type: "tilelayer"},
+		{Name: "Roads", Type: "group", Layers: []Layer{
+			{Name: "Sidewalks", Type: "tilelayer"},
+			{Name: "Roads", Type: "tilelayer"}, // same name as its group, like the NYC map
+		}},
+	}}
+	if l := m.FindLayer("Roads"); l == nil || l.Type != "group" {
+		t.Errorf("FindLayer(Roads) = %+v, want the group", l)
+	}
+	if l := m.FindTileLayer("Roads"); l == nil || l.Type != "tilelayer" {
+		t.Errorf("FindTileLayer(Roads) = %+v, want the layer inside the group", l)
+	}
+	if l := m.FindLayer("Sidewalks"); l != &m.Layers[1].Layers[0] {
+		t.Error("FindLayer didn't look inside the group")
+	}
+	if m.FindLayer("Nope") != nil || m.FindTileLayer("Nope") != nil {
+		t.Error("found a layer that isn't there")
+	}
+}
+
+// SetTile only marks the chunk holding the tile for re-baking
+func TestSetTile(t *testing.T) {
+	m := &Map{Width: 40, Height: 20, TileWidth: 16, TileHeight: 16, Layers: []Layer{
+		{Name: "Ground", Type: "tilelayer", Visible: true, Data: make([]uint32, 40*20)},
+		{Name: "Spawns", Type: "objectgroup", Visible: true},
+	}}
+	r := NewRenderer(m, nil, 1)
+	lc := r.cacheFor(&m.Layers[0])
+	if lc.cols != 3 || lc.rows != 2 {
+		t.Fatalf("40x20 tiles in %dx%d chunks, want 3x2", lc.cols, lc.rows)
+	}
+	for i := range lc.chunks {
+		lc.chunks[i].baked = true
+	}
+
+	if !r.SetTile("Ground", 17, 18, 5) {
+		t.Fatal("SetTile failed")
+	}
+	if got := m.Layers[0].Data[18*40+17]; got != 5 {
+		t.Errorf("tile is %d, want 5", got)
+	}
+	for i, c := range lc.chunks {
+		if want := i != 1*3+1; c.baked != want {
+			t.Errorf("chunk %d baked = %v, want %v", i, c.baked, want)
+		}
+	}
+
+	for _, bad := range []struct {
+		layer  string
+		tx, ty int
+	}{
+		{"Nope", 0, 0},
+		{"Spawns", 0, 0},
+		{"Ground", 40, 0},
+		{"Ground", 0, -1},
+	} {
+		if r.SetTile(bad.layer, bad.tx, bad.ty, 5) {
+			t.Errorf("SetTile(%q, %d, %d) worked", bad.layer, bad.tx, bad.ty)
+		}
+	}
+}
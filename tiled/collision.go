@@ -1,25 +1,65 @@
 package tiled
 
+import (
+	"math"
+	"strings"
+)
+
+// CollisionGrid is what the player, taxis and NPCs ask "can I go here?".
+//
+// Where it comes from, per tile (first match wins):
+//   - tile property solid=false: never blocks, even on a COLLIDE layer
+//   - tile property solid=true: the whole cell blocks
+//   - shapes from Tiled's collision editor: only those shapes block (curbs, lamp posts, fences)
+//   - old convention: any tile on a layer named "COLLIDE..." blocks the whole cell
+//
+// On top of that, "collider" shapes on object layers are added as partial colliders.
 type CollisionGrid struct {
 	Width  int
 	Height int
 	Solid  [][]bool
+
+	// Tile the first cell (Solid[0][0]) is at. Only infinite maps have anything but 0,0.
+	OriginX int
+	OriginY int
+
+	// Surface is the "surface" property of the tile on top ("road", "sidewalk", "park"...), "" if none
+	Surface [][]string
+
+	// Map pixels per cell, and world pixels per map pixel (the NYC map is drawn at 2x)
+	TileWidth  int
+	TileHeight int
+	Scale      float64
+
+	// Partial colliders in map pixels, cellShapes indexes them by the cells they touch
+	Shapes     []Shape
+	cellShapes map[int][]int
 }
 
 func BuildCollisionGrid(m *Map) *CollisionGrid {
+	b := m.Bounds()
 	grid := &CollisionGrid{
-		Width:  m.Width,
-		Height: m.Height,
-		Solid:  make([][]bool, m.Height),
+		Width:      b.Dx(),
+		Height:     b.Dy(),
+		OriginX:    b.Min.X,
+		OriginY:    b.Min.Y,
+		Solid:      make([][]bool, b.Dy()),
+		Surface:    make([][]string, b.Dy()),
+		TileWidth:  m.TileWidth,
+		TileHeight: m.TileHeight,
+		Scale:      1,
+		cellShapes: map[int][]int{},
 	}
 
-	for y := range m.Height {
-		grid.Solid[y] = make([]bool, m.Width)
+	for y := range grid.Height {
+		grid.Solid[y] = make([]bool, grid.Width)
+		grid.Surface[y] = make([]string, grid.Width)
 	}
 
 	var walk func(layers []Layer)
 	walk = func(layers []Layer) {
-		for _, layer := range layers {
+		for i := range layers {
+			layer := &layers[i]
 			if layer.Type == "group" {
 				walk(layer.Layers)
 				continue
@@ -29,22 +69,206 @@ func BuildCollisionGrid(m *Map) *CollisionGrid {
 				continue
 			}
 
-			if !IsCollideLayer(layer.Name) {
-				continue
-			}
-
-			for i, gid := range layer.Data {
-				if gid == 0 {
-					continue
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					if gid := m.TileAt(layer, x, y); gid != 0 {
+						grid.addTile(m, layer.Name, gid, x, y)
+					}
 				}
-
-				x := i % m.Width
-				y := i / m.Width
-				grid.Solid[y][x] = true
 			}
 		}
 	}
 
 	walk(m.Layers)
+
+	// Walls drawn straight on object layers (one-way barriers, construction fences...)
+	walkObjects(m.Layers, func(_ *Layer, obj *Object) {
+		if !isObjectCollider(obj) {
+			return
+		}
+		if shape, ok := ObjectShape(obj); ok {
+			grid.AddShape(shape)
+		}
+	})
 	return grid
 }
+
+// addTile applies one tile at map tile (x, y) to the grid
+func (g *CollisionGrid) addTile(m *Map, layerName string, raw uint32, x, y int) {
+	var ts *Tileset
+	var td *TileDef
+	if idx, local := m.TilesetForGID(raw); idx >= 0 {
+		ts = &m.Tilesets[idx]
+		td = ts.Tile(local)
+	}
+
+	var solid, hasSolid bool
+	if td != nil {
+		if td.Properties.Has("solid") {
+			solid, hasSolid = td.Properties.Bool("solid", false), true
+		}
+		if surface := td.Properties.String("surface", ""); surface != "" {
+			g.Surface[y-g.OriginY][x-g.OriginX] = surface
+		}
+	}
+
+	switch {
+	case hasSolid:
+		if solid {
+			g.Solid[y-g.OriginY][x-g.OriginX] = true
+		}
+	case td != nil && td.ObjectGroup != nil && len(td.ObjectGroup.Objects) > 0:
+		g.addTileShapes(m, ts, td, raw, x, y)
+	case IsCollideLayer(layerName):
+		g.Solid[y-g.OriginY][x-g.OriginX] = true
+	}
+}
+
+// addTileShapes places a tile's collision-editor shapes in the world, flipped like the tile is
+func (g *CollisionGrid) addTileShapes(m *Map, ts *Tileset, td *TileDef, raw uint32, x, y int) {
+	tw, th := float64(ts.TileWidth), float64(ts.TileHeight)
+
+	// Same anchoring as the renderer: bottom-left of the tile, as drawn, on bottom-left
+	// of the cell. A diagonal flip swaps the tile's width and height, see tileTransform.
+	_, _, outH := tileTransform(raw, tw, th, m.Orientation == "hexagonal")
+	ox := float64(x * m.TileWidth)
+	oy := float64((y+1)*m.TileHeight) - outH
+
+	for i := range td.ObjectGroup.Objects {
+		shape, ok := ObjectShape(&td.ObjectGroup.Objects[i])
+		if !ok {
+			continue
+		}
+
+		points := make([]Point, len(shape.Points))
+		for j, p := range shape.Points {
+			points[j] = flipPoint(p, raw, tw, th)
+		}
+		g.AddShape(NewShape(points).Translate(ox, oy))
+	}
+}
+
+// flipPoint moves a point in tile space the same way the tile's flip bits move its pixels
+// (diagonal first, then horizontal, then vertical). Hex rotations aren't handled here.
+func flipPoint(p Point, raw uint32, w, h float64) Point {
+	if raw&flipD != 0 {
+		p.X, p.Y = p.Y, p.X
+		w, h = h, w
+	}
+	if raw&flipH != 0 {
+		p.X = w - p.X
+	}
+	if raw&flipV != 0 {
+		p.Y = h - p.Y
+	}
+	return p
+}
+
+// AddShape adds a partial collider (in map pixels) to the grid
+func (g *CollisionGrid) AddShape(s Shape) {
+	if g.cellShapes == nil {
+		g.cellShapes = map[int][]int{}
+	}
+
+	idx := len(g.Shapes)
+	g.Shapes = append(g.Shapes, s)
+
+	x0, y0, x1, y1 := g.cellRange(s.MinX, s.MinY, s.MaxX-s.MinX, s.MaxY-s.MinY)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			cell := y*g.Width + x
+			g.cellShapes[cell] = append(g.cellShapes[cell], idx)
+		}
+	}
+}
+
+// cellRange is the (clamped) range of cells a map-pixel rectangle touches, as Solid indexes
+func (g *CollisionGrid) cellRange(x, y, w, h float64) (x0, y0, x1, y1 int) {
+	tw, th := float64(g.TileWidth), float64(g.TileHeight)
+	x0 = max(0, int(math.Floor(x/tw))-g.OriginX)
+	y0 = max(0, int(math.Floor(y/th))-g.OriginY)
+	x1 = min(g.Width-1, int(math.Ceil((x+w)/tw))-1-g.OriginX)
+	y1 = min(g.Height-1, int(math.Ceil((y+h)/th))-1-g.OriginY)
+	return
+}
+
+func (g *CollisionGrid) scale() float64 {
+	if g.Scale <= 0 {
+		return 1
+	}
+	return g.Scale
+}
+
+// RectCollides reports whether a world-space rectangle hits anything solid.
+// Anything outside the map doesn't collide (the scene clamps to the world instead).
+func (g *CollisionGrid) RectCollides(x, y, w, h float64) bool {
+	s := g.scale()
+	x, y, w, h = x/s, y/s, w/s, h/s
+
+	x0, y0, x1, y1 := g.cellRange(x, y, w, h)
+	for cy := y0; cy <= y1; cy++ {
+		for cx := x0; cx <= x1; cx++ {
+			if g.Solid[cy][cx] {
+				return true
+			}
+			for _, i := range g.cellShapes[cy*g.Width+cx] {
+				if g.Shapes[i].OverlapsRect(x, y, w, h) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// PointCollides reports whether a world-space point is inside anything solid
+func (g *CollisionGrid) PointCollides(x, y float64) bool {
+	s := g.scale()
+	x, y = x/s, y/s
+
+	cx := int(math.Floor(x/float64(g.TileWidth))) - g.OriginX
+	cy := int(math.Floor(y/float64(g.TileHeight))) - g.OriginY
+	if cx < 0 || cy < 0 || cx >= g.Width || cy >= g.Height {
+		return false
+	}
+	if g.Solid[cy][cx] {
+		return true
+	}
+	for _, i := range g.cellShapes[cy*g.Width+cx] {
+		if g.Shapes[i].Contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// CellAt returns the grid cell (Solid indexes) under a world-space point, ok is false off the map
+func (g *CollisionGrid) CellAt(x, y float64) (cx, cy int, ok bool) {
+	s := g.scale()
+	cx = int(math.Floor(x/s/float64(g.TileWidth))) - g.OriginX
+	cy = int(math.Floor(y/s/float64(g.TileHeight))) - g.OriginY
+	return cx, cy, cx >= 0 && cy >= 0 && cx < g.Width && cy < g.Height
+}
+
+// CellCenter is the world-space middle of a grid cell
+func (g *CollisionGrid) CellCenter(cx, cy int) (float64, float64) {
+	s := g.scale()
+	return (float64(cx+g.OriginX) + 0.5) * float64(g.TileWidth) * s,
+		(float64(cy+g.OriginY) + 0.5) * float64(g.TileHeight) * s
+}
+
+// SurfaceAt returns the surface property under a world-space point ("" if none)
+func (g *CollisionGrid) SurfaceAt(x, y float64) string {
+	s := g.scale()
+	cx := int(math.Floor(x/s/float64(g.TileWidth))) - g.OriginX
+	cy := int(math.Floor(y/s/float64(g.TileHeight))) - g.OriginY
+	if cx < 0 || cy < 0 || cx >= g.Width || cy >= g.Height {
+		return ""
+	}
+	return g.Surface[cy][cx]
+}
+
+// IsCollideLayer is the old layer-name convention, still used for tiles with no collision data
+func IsCollideLayer(name string) bool {
+	return strings.Contains(strings.ToUpper(name), "COLLIDE")
+}
@@ -0,0 +1,123 @@
+package tiled
+
+import (
+	"slices"
+	"testing"
+)
+
+// tileRow is a one-row tile layer
+func tileRow(name string, gids ...uint32) Layer {
+	return Layer{Name: name, Type: "tilelayer", Visible: true, Data: gids}
+}
+
+func TestCollisionGridTiles(t *testing.T) {
+	m := &Map{
+		Width: 5, Height: 1, TileWidth: 16, TileHeight: 16,
+		Tilesets: []Tileset{{
+			FirstGID: 1, TileWidth: 16, TileHeight: 16, TileCount: 8,
+			Tiles: []TileDef{
+				{ID: 1, Properties: Properties{{Name: "solid", Type: "bool", Value: true}}},
+				{ID: 2, Properties: Properties{{Name: "solid", Type: "bool", Value: false}}},
+				{ID: 3, Properties: Properties{{Name: "surface", Type: "string", Value: "park"}}},
+				// A curb along the bottom half of the tile
+				{ID: 4, ObjectGroup: &Layer{Objects: []Object{{X: 0, Y: 8, Width: 16, Height: 8}}}},
+			},
+		}},
+		Layers: []Layer{
+			tileRow("Ground", 2, 4, 5, 0, 0),
+			tileRow("COLLIDE walls", 0, 0, 0, 1, 3),
+		},
+	}
+	g := BuildCollisionGrid(m)
+
+	// solid=true, surface only, shapes only, COLLIDE layer, solid=false on a COLLIDE layer
+	if want := []bool{true, false, false, true, false}; !slices.Equal(g.Solid[0], want) {
+		t.Errorf("Solid = %v, want %v", g.Solid[0], want)
+	}
+	if got := g.SurfaceAt(24, 8); got != "park" {
+		t.Errorf("SurfaceAt park tile = %q", got)
+	}
+	if len(g.Shapes) != 1 {
+		t.Fatalf("got %d shapes, want the curb", len(g.Shapes))
+	}
+
+	points := []struct {
+		x, y float64
+		want bool
+	}{
+		{8, 8, true},   // solid tile
+		{24, 8, false}, // park
+		{40, 4, false}, // above the curb
+		{40, 12, true}, // on it
+		{56, 8, true},  // COLLIDE layer
+		{72, 8, false}, // solid=false wins over the layer name
+		{-8, 8, false}, // off the map
+	}
+	for _, p := range points {
+		if got := g.PointCollides(p.x, p.y); got != p.want {
+			t.Errorf("PointCollides(%v, %v) = %v, want %v", p.x, p.y, got, p.want)
+		}
+	}
+	if g.RectCollides(34, 0, 12, 6) || !g.RectCollides(34, 0, 12, 10) {
+		t.Error("RectCollides should only hit the curb's half of the tile")
+	}
+
+	// World pixels at 2x
+	g.Scale = 2
+	if !g.PointCollides(80, 24) || g.PointCollides(80, 8) {
+		t.Error("PointCollides ignores Scale")
+	}
+}
+
+// A 16x32 tile (taller than the 16px grid, like a lamp post) with a 4x8 collider in its
+// top-left corner, placed at cell (1, 3). The collider has to end up on the sprite,
+// wherever the flip bits put it.
+func TestCollisionTileShapeFlips(t *testing.T) {
+	tests := []struct {
+		name                   string
+		flips                  uint32
+		minX, minY, maxX, maxY float64
+	}{
+		{"none", 0, 16, 32, 20, 40},
+		{"horizontal", flipH, 28, 32, 32, 40},
+		{"vertical", flipV, 16, 56, 20, 64},
+		{"both", flipH | flipV, 28, 56, 32, 64},
+		// Diagonal flips turn it into a 32x16 tile, one cell high
+		{"diagonal", flipD, 16, 48, 24, 52},
+		{"diagonal horizontal", flipD | flipH, 40, 48, 48, 52},
+		{"diagonal vertical", flipD | flipV, 16, 60, 24, 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]uint32, 4*4)
+			data[3*4+1] = tt.flips | 1
+			m := &Map{
+				Width: 4, Height: 4, TileWidth: 16, TileHeight: 16,
+				Tilesets: []Tileset{{
+					FirstGID: 1, TileWidth: 16, TileHeight: 32, TileCount: 1,
+					Tiles: []TileDef{{ID: 0, ObjectGroup: &Layer{Objects: []Object{{Width: 4, Height: 8}}}}},
+				}},
+				Layers: []Layer{{Name: "Props", Type: "tilelayer", Visible: true, Data: data}},
+			}
+			g := BuildCollisionGrid(m)
+			if len(g.Shapes) != 1 {
+				t.Fatalf("got %d shapes, want 1", len(g.Shapes))
+			}
+			s := g.Shapes[0]
+			if s.MinX != tt.minX || s.MinY != tt.minY || s.MaxX != tt.maxX || s.MaxY != tt.maxY {
+				t.Errorf("collider at (%v,%v)-(%v,%v), want (%v,%v)-(%v,%v)",
+					s.MinX, s.MinY, s.MaxX, s.MaxY, tt.minX, tt.minY, tt.maxX, tt.maxY)
+			}
+
+			// Same place as the renderer puts those pixels
+			geo, _, outH := tileTransform(tt.flips, 16, 32, false)
+			geo.Translate(16, 4*16-outH)
+			x0, y0 := geo.Apply(0, 0)
+			x1, y1 := geo.Apply(4, 8)
+			if min(x0, x1) != s.MinX || min(y0, y1) != s.MinY || max(x0, x1) != s.MaxX || max(y0, y1) != s.MaxY {
+				t.Errorf("sprite corner at (%v,%v)-(%v,%v), collider at (%v,%v)-(%v,%v)",
+					min(x0, x1), min(y0, y1), max(x0, x1), max(y0, y1), s.MinX, s.MinY, s.MaxX, s.MaxY)
+			}
+		})
+	}
+}
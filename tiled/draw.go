@@ -1,42 +1,79 @@
 package tiled
 
 import (
-	"image"
-	"strings"
+	"math"
+	"slices"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-const (
-	flipH    = 0x80000000
-	flipV    = 0x40000000
-	flipD    = 0x20000000
-	flipMask = flipH | flipV | flipD
-)
-
 type Renderer struct {
-	Map     *Map
-	Tileset *ebiten.Image
-	Scale   float64
+	Map *Map
+	// Images holds one image per tileset, same order as Map.Tilesets.
+	// A nil entry means that tileset's tiles are skipped.
+	Images []*ebiten.Image
+	Scale  float64
+
+	// Clock drives tile animations. The renderer never advances it on its own: whoever
+	// owns the game's time sets it every tick (the race copies its RaceClock in), so
+	// animations pause and speed up with the game.
+	Clock time.Duration
+
+	// Baked chunks per tile layer, see chunk.go
+	cache map[*Layer]*layerCache
+	// Animated tiles by GID (no flip bits), built on first use
+	anims map[uint32]*TileDef
 }
 
-func NewRenderer(m *Map, tileset *ebiten.Image, scale float64) *Renderer {
+func NewRenderer(m *Map, images []*ebiten.Image, scale float64) *Renderer {
 	return &Renderer{
-		Map:     m,
-		Tileset: tileset,
-		Scale:   scale,
+		Map:    m,
+		Images: images,
+		Scale:  scale,
 	}
 }
 
 func (r *Renderer) Draw(screen *ebiten.Image, camX, camY float64) {
-	for _, layer := range r.Map.Layers {
-		r.drawLayer(screen, layer, camX, camY)
+	for i := range r.Map.Layers {
+		r.drawLayer(screen, &r.Map.Layers[i], camX, camY)
+	}
+}
+
+// DrawLayer draws a single layer by name. If the name is a group, everything
+// inside it is drawn. Returns false if there is no such layer.
+func (r *Renderer) DrawLayer(screen *ebiten.Image, name string, camX, camY float64) bool {
+	layer := r.Map.FindLayer(name)
+	if layer == nil {
+		return false
+	}
+	r.drawLayer(screen, layer, camX, camY)
+	return true
+}
+
+// DrawLayersExcept draws the whole map in order but leaves out the named layers/groups,
+// so they can be drawn later on top of entities with DrawLayer.
+func (r *Renderer) DrawLayersExcept(screen *ebiten.Image, camX, camY float64, skip ...string) {
+	var walk func(layers []Layer)
+	walk = func(layers []Layer) {
+		for i := range layers {
+			layer := &layers[i]
+			if slices.Contains(skip, layer.Name) || !layer.Visible {
+				continue
+			}
+			if layer.Type == "group" {
+				walk(layer.Layers)
+				continue
+			}
+			r.drawLayer(screen, layer, camX, camY)
+		}
 	}
+	walk(r.Map.Layers)
 }
 
 func (r *Renderer) drawLayer(
 	screen *ebiten.Image,
-	layer Layer,
+	layer *Layer,
 	camX, camY float64,
 ) {
 	if !layer.Visible {
@@ -44,8 +81,8 @@ func (r *Renderer) drawLayer(
 	}
 
 	if layer.Type == "group" {
-		for _, sub := range layer.Layers {
-			r.drawLayer(screen, sub, camX, camY)
+		for i := range layer.Layers {
+			r.drawLayer(screen, &layer.Layers[i], camX, camY)
 		}
 		return
 	}
@@ -54,54 +91,117 @@ func (r *Renderer) drawLayer(
 		return
 	}
 
-	tilesPerRow := r.Tileset.Bounds().Dx() / r.Map.TileWidth
+	// Tile layers are baked into chunks once and only the ones on screen get drawn
+	r.drawChunks(screen, layer, camX, camY)
+}
 
-	for i, raw := range layer.Data {
-		if raw == 0 {
-			continue
-		}
+// drawTile draws one raw GID at map cell (x, y). Positions are in unscaled map
+// pixels, geo is applied afterwards (scale + camera, or a chunk offset).
+func (r *Renderer) drawTile(dst *ebiten.Image, raw uint32, x, y int, geo ebiten.GeoM) {
+	tile, ts := r.tileImage(raw)
+	if tile == nil {
+		return
+	}
+
+	op := &ebiten.DrawImageOptions{}
 
-		gid := raw &^ flipMask
-		tileIndex := int(gid - 1)
+	// flips and rotations
+	var th float64
+	op.GeoM, _, th = tileTransform(raw, float64(ts.TileWidth), float64(ts.TileHeight), r.Map.Orientation == "hexagonal")
 
-		sx := (tileIndex % tilesPerRow) * r.Map.TileWidth
-		sy := (tileIndex / tilesPerRow) * r.Map.TileHeight
+	// Tiles bigger than the map grid (trees, signs) are anchored bottom-left like in Tiled
+	op.GeoM.Translate(
+		float64(x*r.Map.TileWidth),
+		float64((y+1)*r.Map.TileHeight)-th,
+	)
+	op.GeoM.Concat(geo)
 
-		tile := r.Tileset.SubImage(
-			image.Rect(
-				sx,
-				sy,
-				sx+r.Map.TileWidth,
-				sy+r.Map.TileHeight,
-			),
-		).(*ebiten.Image)
+	dst.DrawImage(tile, op)
+}
 
-		x := i % r.Map.Width
-		y := i / r.Map.Width
+// tileTransform turns the flip bits of a raw GID into a GeoM for a w x h tile.
+// The result still covers (0,0)-(outW,outH), so callers only need to translate it.
+//
+// Orthogonal maps: D is a flip over the top-left/bottom-right diagonal and is applied
+// before H and V, so D+H is 90° clockwise, D+V is 90° counter-clockwise, H+V is 180°.
+// Hexagonal maps: D means a 60° turn and flipHex a 120° turn, after the H/V flips.
+func tileTransform(raw uint32, w, h float64, hex bool) (g ebiten.GeoM, outW, outH float64) {
+	outW, outH = w, h
+
+	if raw&flipD != 0 && !hex {
+		// swap x and y
+		g.SetElement(0, 0, 0)
+		g.SetElement(0, 1, 1)
+		g.SetElement(1, 0, 1)
+		g.SetElement(1, 1, 0)
+		outW, outH = h, w
+	}
 
-		op := &ebiten.DrawImageOptions{}
+	if raw&flipH != 0 {
+		g.Scale(-1, 1)
+		g.Translate(outW, 0)
+	}
+	if raw&flipV != 0 {
+		g.Scale(1, -1)
+		g.Translate(0, outH)
+	}
 
-		// flips
-		if raw&flipH != 0 {
-			op.GeoM.Scale(-1, 1)
-			op.GeoM.Translate(float64(r.Map.TileWidth), 0)
+	if hex {
+		deg := 0.0
+		if raw&flipD != 0 {
+			deg += 60
 		}
-		if raw&flipV != 0 {
-			op.GeoM.Scale(1, -1)
-			op.GeoM.Translate(0, float64(r.Map.TileHeight))
+		if raw&flipHex != 0 {
+			deg += 120
 		}
+		if deg != 0 {
+			g.Translate(-w/2, -h/2)
+			g.Rotate(deg * math.Pi / 180)
+			g.Translate(w/2, h/2)
+		}
+	}
+
+	return g, outW, outH
+}
 
-		op.GeoM.Scale(r.Scale, r.Scale)
-		op.GeoM.Translate(
-			float64(x*r.Map.TileWidth)*r.Scale-camX,
-			float64(y*r.Map.TileHeight)*r.Scale-camY,
-		)
+// animation returns the animated tile behind a raw GID, or nil if it's a plain tile
+func (r *Renderer) animation(raw uint32) *TileDef {
+	if r.anims == nil {
+		r.anims = map[uint32]*TileDef{}
+		for i := range r.Map.Tilesets {
+			ts := &r.Map.Tilesets[i]
+			for j := range ts.Tiles {
+				if len(ts.Tiles[j].Animation) > 0 {
+					r.anims[uint32(ts.FirstGID+ts.Tiles[j].ID)] = &ts.Tiles[j]
+				}
+			}
+		}
+	}
+	return r.anims[raw&^flipMask]
+}
 
-		screen.DrawImage(tile, op)
+// animatedGID swaps the tile id of an animated GID for the frame showing right now, keeping the flip bits
+func (r *Renderer) animatedGID(raw uint32, td *TileDef) uint32 {
+	idx, _ := r.Map.TilesetForGID(raw)
+	if idx < 0 {
+		return raw
 	}
+	frame := td.FrameAt(r.Clock.Milliseconds())
+	return raw&flipMask | uint32(r.Map.Tilesets[idx].FirstGID+frame)
 }
 
-// Utility for later collision logic
-func IsCollideLayer(name string) bool {
-	return strings.Contains(strings.ToUpper(name), "COLLIDE")
+// tileImage looks up the tileset that owns a GID and cuts the tile out of its image
+func (r *Renderer) tileImage(raw uint32) (*ebiten.Image, *Tileset) {
+	idx, local := r.Map.TilesetForGID(raw)
+	if idx < 0 || idx >= len(r.Images) || r.Images[idx] == nil {
+		return nil, nil
+	}
+
+	ts := &r.Map.Tilesets[idx]
+	img := r.Images[idx]
+	rect := ts.TileRect(local, img.Bounds().Dx())
+	if rect.Empty() {
+		return nil, nil
+	}
+	return img.SubImage(rect).(*ebiten.Image), ts
 }
@@ -0,0 +1,85 @@
+package tiled
+
+import (
+	"math"
+	"testing"
+)
+
+// A 16x32 tile: where its top-left and top-right pixels end up for each set of flip bits
+func TestTileTransform(t *testing.T) {
+	tests := []struct {
+		name              string
+		raw               uint32
+		outW, outH        float64
+		topLeft, topRight [2]float64
+	}{
+		{"none", 0, 16, 32, [2]float64{0, 0}, [2]float64{16, 0}},
+		{"horizontal", flipH, 16, 32, [2]float64{16, 0}, [2]float64{0, 0}},
+		{"vertical", flipV, 16, 32, [2]float64{0, 32}, [2]float64{16, 32}},
+		{"180", flipH | flipV, 16, 32, [2]float64{16, 32}, [2]float64{0, 32}},
+		{"diagonal", flipD, 32, 16, [2]float64{0, 0}, [2]float64{0, 16}},
+		{"90 clockwise", flipD | flipH, 32, 16, [2]float64{32, 0}, [2]float64{32, 16}},
+		{"90 counter-clockwise", flipD | flipV, 32, 16, [2]float64{0, 16}, [2]float64{0, 0}},
+		{"gid bits don't matter", flipH | 1234, 16, 32, [2]float64{16, 0}, [2]float64{0, 0}},
+	}
+	for _, tt := range tests {
+		g, w, h := tileTransform(tt.raw, 16, 32, false)
+		if w != tt.outW || h != tt.outH {
+			t.Errorf("%s: out %vx%v, want %vx%v", tt.name, w, h, tt.outW, tt.outH)
+		}
+		if x, y := g.Apply(0, 0); x != tt.topLeft[0] || y != tt.topLeft[1] {
+			t.Errorf("%s: top-left to %v,%v, want %v", tt.name, x, y, tt.topLeft)
+		}
+		if x, y := g.Apply(16, 0); x != tt.topRight[0] || y != tt.topRight[1] {
+			t.Errorf("%s: top-right to %v,%v, want %v", tt.name, x, y, tt.topRight)
+		}
+	}
+}
+
+func TestTileTransformHex(t *testing.T) {
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	tests := []struct {
+		name string
+		raw  uint32
+		deg  float64
+	}{
+		{"60", flipD, 60},
+		{"120", flipHex, 120},
+		{"180", flipD | flipHex, 180},
+	}
+	for _, tt := range tests {
+		g, w, h := tileTransform(tt.raw, 20, 16, true)
+		if w != 20 || h != 16 {
+			t.Errorf("%s: hex turns keep the tile size, got %vx%v", tt.name, w, h)
+		}
+		// Turns around the middle of the tile
+		if x, y := g.Apply(10, 8); !near(x, 10) || !near(y, 8) {
+			t.Errorf("%s: center moved to %v,%v", tt.name, x, y)
+		}
+		rad := tt.deg * math.Pi / 180
+		if x, y := g.Apply(20, 8); !near(x, 10+10*math.Cos(rad)) || !near(y, 8+10*math.Sin(rad)) {
+			t.Errorf("%s: right edge at %v,%v, want a %v° turn", tt.name, x, y, tt.deg)
+		}
+	}
+}
+
+// Layers picked out by name, or skipped, never reach the screen (nil here, it would panic)
+func TestDrawLayerByName(t *testing.T) {
+	m := &Map{Width: 2, Height: 1, TileWidth: 16, TileHeight: 16, Layers: []Layer{
+		{Name: "Ground", Type: "tilelayer", Visible: true, Data: []uint32{1, 1}},
+		{Name: "Overhead", Type: "group", Visible: true, Layers: []Layer{
+			{Name: "Roofs", Type: "tilelayer", Visible: true, Data: []uint32{1, 0}},
+		}},
+		{Name: "Spawns", Type: "objectgroup", Visible: true},
+		{Name: "Hidden", Type: "tilelayer", Data: []uint32{1, 1}},
+	}}
+	r := NewRenderer(m, nil, 1)
+
+	if r.DrawLayer(nil, "Nope", 0, 0) {
+		t.Error("DrawLayer found a layer that isn't there")
+	}
+	if !r.DrawLayer(nil, "Spawns", 0, 0) || !r.DrawLayer(nil, "Hidden", 0, 0) {
+		t.Error("DrawLayer should find object and hidden layers, there's just nothing to draw")
+	}
+	r.DrawLayersExcept(nil, 0, 0, "Ground", "Overhead") // the whole group goes with its name
+}
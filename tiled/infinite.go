@@ -0,0 +1,138 @@
+package tiled
+
+import "image"
+
+// Infinite maps don't have one Width*Height data array. Every tile layer is a list of
+// chunks placed anywhere on the grid (negative coordinates included). At load time the
+// chunks are copied into a sparse store of ChunkSize blocks, and Map.TileAt reads
+// either that or Layer.Data, so the rest of the package doesn't care which kind it got.
+
+// Chunk is one block of tiles of an infinite map's layer. X and Y are in tiles.
+type Chunk struct {
+	X      int      `json:"x"`
+	Y      int      `json:"y"`
+	Width  int      `json:"width"`
+	Height int      `json:"height"`
+	Data   []uint32 `json:"data"`
+}
+
+// sparseTiles holds ChunkSize x ChunkSize blocks, keyed by block coordinates
+type sparseTiles map[image.Point][]uint32
+
+// floorDiv divides rounding towards negative infinity, so -1/16 is block -1, not 0
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func (s sparseTiles) get(x, y int) uint32 {
+	bx, by := floorDiv(x, ChunkSize), floorDiv(y, ChunkSize)
+	block, ok := s[image.Pt(bx, by)]
+	if !ok {
+		return 0
+	}
+	return block[(y-by*ChunkSize)*ChunkSize+(x-bx*ChunkSize)]
+}
+
+func (s sparseTiles) set(x, y int, gid uint32) {
+	bx, by := floorDiv(x, ChunkSize), floorDiv(y, ChunkSize)
+	key := image.Pt(bx, by)
+	block, ok := s[key]
+	if !ok {
+		if gid == 0 {
+			return
+		}
+		block = make([]uint32, ChunkSize*ChunkSize)
+		s[key] = block
+	}
+	block[(y-by*ChunkSize)*ChunkSize+(x-bx*ChunkSize)] = gid
+}
+
+// prepareInfinite fills the sparse stores and works out the map bounds.
+// Does nothing for fixed-size maps.
+func (m *Map) prepareInfinite() {
+	if !m.Infinite {
+		return
+	}
+
+	var bounds image.Rectangle
+	var walk func(layers []Layer)
+	walk = func(layers []Layer) {
+		for i := range layers {
+			layer := &layers[i]
+			if layer.Type == "group" {
+				walk(layer.Layers)
+				continue
+			}
+			if layer.Type != "tilelayer" {
+				continue
+			}
+
+			layer.sparse = sparseTiles{}
+			for _, c := range layer.Chunks {
+				if c.Width <= 0 || c.Height <= 0 {
+					continue
+				}
+				for j, gid := range c.Data {
+					if gid != 0 {
+						layer.sparse.set(c.X+j%c.Width, c.Y+j/c.Width, gid)
+					}
+				}
+				bounds = bounds.Union(image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height))
+			}
+		}
+	}
+	walk(m.Layers)
+	m.bounds = bounds
+}
+
+// Bounds is the area of the map that has tiles, in tiles. It's (0,0)-(Width,Height)
+// for normal maps; for infinite maps it covers every chunk and can start below zero.
+func (m *Map) Bounds() image.Rectangle {
+	if !m.Infinite {
+		return image.Rect(0, 0, m.Width, m.Height)
+	}
+	return m.bounds
+}
+
+// PixelBounds is Bounds in map pixels
+func (m *Map) PixelBounds() image.Rectangle {
+	b := m.Bounds()
+	return image.Rect(b.Min.X*m.TileWidth, b.Min.Y*m.TileHeight, b.Max.X*m.TileWidth, b.Max.Y*m.TileHeight)
+}
+
+// TileAt returns the raw GID (with flip bits) of a layer at tile (x, y), 0 if empty or off the map
+func (m *Map) TileAt(layer *Layer, x, y int) uint32 {
+	if layer.sparse != nil {
+		return layer.sparse.get(x, y)
+	}
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
+		return 0
+	}
+	idx := y*m.Width + x
+	if idx >= len(layer.Data) {
+		return 0
+	}
+	return layer.Data[idx]
+}
+
+// setTileAt changes one tile. Infinite maps can't grow past their bounds this way,
+// so the renderer and collision grid sizes stay valid.
+func (m *Map) setTileAt(layer *Layer, x, y int, gid uint32) bool {
+	if !image.Pt(x, y).In(m.Bounds()) {
+		return false
+	}
+	if layer.sparse != nil {
+		layer.sparse.set(x, y, gid)
+		return true
+	}
+	idx := y*m.Width + x
+	if idx >= len(layer.Data) {
+		return false
+	}
+	layer.Data[idx] = gid
+	return true
+}
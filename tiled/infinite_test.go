@@ -0,0 +1,108 @@
+package tiled
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestFloorDiv(t *testing.T) {
+	tests := []struct{ a, b, want int }{
+		{0, 16, 0},
+		{15, 16, 0},
+		{16, 16, 1},
+		{-1, 16, -1},
+		{-16, 16, -1},
+		{-17, 16, -2},
+		{5, -2, -3},
+		{-5, -2, 2},
+	}
+	for _, tt := range tests {
+		if got := floorDiv(tt.a, tt.b); got != tt.want {
+			t.Errorf("floorDiv(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSparseTiles(t *testing.T) {
+	s := sparseTiles{}
+	s.set(3, 4, 0) // an empty tile doesn't allocate a block
+	if len(s) != 0 {
+		t.Fatalf("setting 0 allocated %d blocks", len(s))
+	}
+
+	cells := map[image.Point]uint32{
+		{0, 0}:                         1,
+		{-1, -1}:                       2,
+		{ChunkSize - 1, ChunkSize - 1}: 3,
+		{ChunkSize, 0}:                 4,
+		{-ChunkSize, 5}:                5,
+		{-ChunkSize - 1, 5}:            6,
+	}
+	for p, gid := range cells {
+		s.set(p.X, p.Y, gid)
+	}
+	for p, gid := range cells {
+		if got := s.get(p.X, p.Y); got != gid {
+			t.Errorf("get(%d, %d) = %d, want %d", p.X, p.Y, got, gid)
+		}
+	}
+	if got := s.get(100, -100); got != 0 {
+		t.Errorf("get in a missing block = %d, want 0", got)
+	}
+}
+
+var testInfiniteTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map orientation="orthogonal" width="30" height="20" tilewidth="8" tileheight="8" infinite="1">
+ <layer id="1" name="Ground" width="30" height="20">
+  <data encoding="csv">
+   <chunk x="-16" y="-16" width="16" height="16">` + testChunkCSV + `</chunk>
+   <chunk x="16" y="0" width="2" height="1">7,8</chunk>
+  </data>
+ </layer>
+</map>`
+
+// testChunkCSV is a 16x16 chunk with 9 in its bottom-right corner, everything else empty
+var testChunkCSV = strings.Repeat("0,", 16*16-1) + "9"
+
+func TestInfiniteChunks(t *testing.T) {
+	m, err := decodeTMX([]byte(testInfiniteTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.prepareInfinite()
+
+	if want := image.Rect(-16, -16, 18, 1); m.Bounds() != want {
+		t.Errorf("Bounds = %v, want %v", m.Bounds(), want)
+	}
+	if want := image.Rect(-128, -128, 144, 8); m.PixelBounds() != want {
+		t.Errorf("PixelBounds = %v, want %v", m.PixelBounds(), want)
+	}
+
+	ground := m.FindTileLayer("Ground")
+	tests := []struct {
+		x, y int
+		want uint32
+	}{
+		{-1, -1, 9},
+		{-16, -16, 0},
+		{16, 0, 7},
+		{17, 0, 8},
+		{18, 0, 0},
+		{0, 0, 0},
+		{500, 500, 0},
+	}
+	for _, tt := range tests {
+		if got := m.TileAt(ground, tt.x, tt.y); got != tt.want {
+			t.Errorf("TileAt(%d, %d) = %d, want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	// Edits stay inside the bounds
+	if !m.setTileAt(ground, -2, -2, 5) || m.TileAt(ground, -2, -2) != 5 {
+		t.Error("setTileAt inside the bounds failed")
+	}
+	if m.setTileAt(ground, 40, 40, 5) {
+		t.Error("setTileAt outside the bounds should refuse")
+	}
+}
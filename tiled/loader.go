@@ -2,19 +2,93 @@ package tiled
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/fs"
+	"path"
+	"strings"
 )
 
-func LoadMapFS(fsys fs.FS, path string) (*Map, error) {
-	b, err := fs.ReadFile(fsys, path)
+// LoadMapFS loads a Tiled map, either JSON (.tmj/.json) or XML (.tmx).
+// External tilesets and object templates are read from the same fs.FS.
+func LoadMapFS(fsys fs.FS, mapPath string) (*Map, error) {
+	b, err := fs.ReadFile(fsys, mapPath)
 	if err != nil {
 		return nil, err
 	}
 
-	var m Map
-	if err := json.Unmarshal(b, &m); err != nil {
+	var m *Map
+	if strings.EqualFold(path.Ext(mapPath), ".tmx") {
+		if m, err = decodeTMX(b); err != nil {
+			return nil, fmt.Errorf("tiled: %s: %w", mapPath, err)
+		}
+	} else {
+		m = &Map{}
+		if err := json.Unmarshal(b, m); err != nil {
+			return nil, err
+		}
+	}
+
+	dir := path.Dir(mapPath)
+	if err := resolveTilesets(fsys, dir, m); err != nil {
+		return nil, err
+	}
+
+	// Objects placed from a template only store what the designer changed
+	if err := resolveTemplates(fsys, dir, m); err != nil {
 		return nil, err
 	}
 
-	return &m, nil
+	m.prepareInfinite()
+
+	return m, nil
+}
+
+// LoadTilesetFS reads an external tileset, either XML (.tsx) or JSON (.tsj/.json).
+// Image is left relative to the tileset file, like Tiled writes it.
+func LoadTilesetFS(fsys fs.FS, tilesetPath string) (*Tileset, error) {
+	b, err := fs.ReadFile(fsys, tilesetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(path.Ext(tilesetPath), ".tsx") {
+		ts, err := decodeTSX(b)
+		if err != nil {
+			return nil, fmt.Errorf("tiled: tileset %s: %w", tilesetPath, err)
+		}
+		return ts, nil
+	}
+
+	var ts Tileset
+	if err := json.Unmarshal(b, &ts); err != nil {
+		return nil, fmt.Errorf("tiled: tileset %s: %w", tilesetPath, err)
+	}
+	return &ts, nil
+}
+
+// resolveTilesets pulls external tilesets into the map and makes every
+// Image path relative to the root of fsys, so callers can load it directly.
+func resolveTilesets(fsys fs.FS, dir string, m *Map) error {
+	for i := range m.Tilesets {
+		ts := &m.Tilesets[i]
+		imageDir := dir
+
+		if ts.Source != "" {
+			p := path.Join(dir, ts.Source)
+			ext, err := LoadTilesetFS(fsys, p)
+			if err != nil {
+				return err
+			}
+
+			ext.FirstGID = ts.FirstGID
+			ext.Source = ts.Source
+			*ts = *ext
+			imageDir = path.Dir(p)
+		}
+
+		if ts.Image != "" {
+			ts.Image = path.Join(imageDir, ts.Image)
+		}
+	}
+	return nil
 }
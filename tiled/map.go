@@ -1,13 +1,22 @@
 package tiled
 
+import "image"
+
 // Map represents a Tiled map
 type Map struct {
-	Width      int       `json:"width"`
-	Height     int       `json:"height"`
-	TileWidth  int       `json:"tilewidth"`
-	TileHeight int       `json:"tileheight"`
-	Layers     []Layer   `json:"layers"`
-	Tilesets   []Tileset `json:"tilesets"`
+	Orientation string    `json:"orientation"` // "orthogonal" | "isometric" | "staggered" | "hexagonal"
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	TileWidth   int       `json:"tilewidth"`
+	TileHeight  int       `json:"tileheight"`
+	Infinite    bool      `json:"infinite"` // layers use Chunks instead of Data, see infinite.go
+	Layers      []Layer   `json:"layers"`
+	Tilesets    []Tileset `json:"tilesets"`
+
+	// Level metadata set in Map > Map Properties (time of day, payout...)
+	Properties Properties `json:"properties,omitempty"`
+
+	bounds image.Rectangle // tiles covered by chunks, only for infinite maps
 }
 
 // Layer represents a layer in Tiled. It can be a tile layer, group, or object layer.
@@ -19,35 +28,87 @@ type Layer struct {
 	Layers  []Layer  `json:"layers"` // for group layers
 	Visible bool     `json:"visible"`
 
+	// Only for tile layers of infinite maps
+	Chunks []Chunk `json:"chunks,omitempty"`
+	sparse sparseTiles
+
+	Properties Properties `json:"properties,omitempty"`
+
 	// Only for object layers
 	Objects []Object `json:"objects,omitempty"`
 }
 
-// Tileset represents a Tiled tileset
+// Tileset represents a Tiled tileset.
+// After LoadMapFS, external tilesets are merged in and Image is a path inside the fs.FS.
 type Tileset struct {
 	FirstGID    int    `json:"firstgid"`
+	Source      string `json:"source,omitempty"` // external .tsx/.tsj, relative to the map
+	Name        string `json:"name"`
 	Image       string `json:"image"`
 	ImageWidth  int    `json:"imagewidth"`
 	ImageHeight int    `json:"imageheight"`
 	TileWidth   int    `json:"tilewidth"`
 	TileHeight  int    `json:"tileheight"`
+	TileCount   int    `json:"tilecount"`
+	Columns     int    `json:"columns"`
+	Margin      int    `json:"margin"`
+	Spacing     int    `json:"spacing"`
+
+	// Pixels of this color ("#RRGGBB") are see-through, "" if the image has real alpha
+	TransparentColor string `json:"transparentcolor,omitempty"`
+
+	Properties Properties `json:"properties,omitempty"`
+
+	// Only tiles that have something special (animation, properties) are listed
+	Tiles []TileDef `json:"tiles,omitempty"`
+}
+
+// TileDef is the extra data Tiled stores for a single tile of a tileset
+type TileDef struct {
+	ID         int        `json:"id"` // local id inside the tileset
+	Type       string     `json:"type"`
+	Properties Properties `json:"properties,omitempty"`
+	Animation  []Frame    `json:"animation,omitempty"`
+
+	// Shapes drawn in Tiled's collision editor, in tile pixels
+	ObjectGroup *Layer `json:"objectgroup,omitempty"`
+}
+
+// Frame is one step of a tile animation
+type Frame struct {
+	TileID   int `json:"tileid"`   // local id inside the same tileset
+	Duration int `json:"duration"` // milliseconds
 }
 
 // Object represents an individual object in an object layer
 type Object struct {
-	ID         int              `json:"id"`
-	Name       string           `json:"name"`
-	Type       string           `json:"type"` // "taxi", "player", "checkpoint", etc.
-	X          float64          `json:"x"`
-	Y          float64          `json:"y"`
-	Properties []ObjectProperty `json:"properties,omitempty"`
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"` // "taxi", "player", "checkpoint", etc.
+	X          float64    `json:"x"`
+	Y          float64    `json:"y"`
+	Width      float64    `json:"width"`
+	Height     float64    `json:"height"`
+	Rotation   float64    `json:"rotation"` // degrees clockwise around (X, Y)
+	Point      bool       `json:"point"`
+	Ellipse    bool       `json:"ellipse"`
+	Polygon    []Point    `json:"polygon,omitempty"`  // relative to (X, Y)
+	Template   string     `json:"template,omitempty"` // path to a .tx/.tj, resolved by LoadMapFS
+	Properties Properties `json:"properties,omitempty"`
+}
+
+// Point is a polygon vertex
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
 }
 
-// ObjectProperty represents a custom property attached to an object
+// ObjectProperty represents a custom property attached to an object (or map, layer, tileset, tile)
 type ObjectProperty struct {
-	Name  string      `json:"name"`
-	Type  string      `json:"type"` // "string", "int", "float", "bool"
-	Value interface{} `json:"value"`
+	Name         string      `json:"name"`
+	Type         string      `json:"type"`                   // "string", "int", "float", "bool", "color", "file", "object", "class"
+	PropertyType string      `json:"propertytype,omitempty"` // name of the custom class, for "class" properties
+	Value        interface{} `json:"value"`
 }
 
 // Spawn is a simplified struct used by your game code
@@ -61,12 +122,55 @@ type Spawn struct {
 
 // Helper: get string property from an object
 func (o *Object) GetStringProperty(name, fallback string) string {
-	for _, p := range o.Properties {
+	return o.Properties.String(name, fallback)
+}
+
+// findProperty returns the raw value of a named property, if it's there
+func findProperty(props []ObjectProperty, name string) (interface{}, bool) {
+	for _, p := range props {
 		if p.Name == name {
-			if str, ok := p.Value.(string); ok {
-				return str
+			return p.Value, true
+		}
+	}
+	return nil, false
+}
+
+// ObjectByID finds an object on any visible object layer, e.g. the target of an object property.
+// Returns nil if there's none.
+func (m *Map) ObjectByID(id int) *Object {
+	if id == 0 {
+		return nil
+	}
+	var found *Object
+	walkObjects(m.Layers, func(_ *Layer, obj *Object) {
+		if found == nil && obj.ID == id {
+			found = obj
+		}
+	})
+	return found
+}
+
+// FindLayer returns the first layer with this name, looking inside groups too
+func (m *Map) FindLayer(name string) *Layer {
+	return findLayer(m.Layers, func(l *Layer) bool { return l.Name == name })
+}
+
+// FindTileLayer is FindLayer but skips groups and object layers with the same name
+// (the NYC map has a "Roads and Sidewalks" group holding a "Roads and Sidewalks" layer)
+func (m *Map) FindTileLayer(name string) *Layer {
+	return findLayer(m.Layers, func(l *Layer) bool { return l.Name == name && l.Type == "tilelayer" })
+}
+
+func findLayer(layers []Layer, match func(*Layer) bool) *Layer {
+	for i := range layers {
+		if match(&layers[i]) {
+			return &layers[i]
+		}
+		if layers[i].Type == "group" {
+			if found := findLayer(layers[i].Layers, match); found != nil {
+				return found
 			}
 		}
 	}
-	return fallback
+	return nil
 }
@@ -0,0 +1,214 @@
+package tiled
+
+import (
+	"container/heap"
+	"image"
+	"math"
+)
+
+// Pathfinder finds routes across a CollisionGrid with A*.
+//
+// Every cell has a price: road is cheapest, sidewalks and parks cost more, solid
+// cells can't be entered at all. Paths are cached by start and goal cell, so rivals
+// heading for the same checkpoint from the same block share one search.
+type Pathfinder struct {
+	Grid  *CollisionGrid
+	Roads *RoadGraph // optional, road tiles cost RoadCost
+
+	RoadCost     float64
+	DefaultCost  float64            // walkable cells that aren't road and have no surface cost (sidewalks)
+	SurfaceCosts map[string]float64 // by the tile "surface" property
+	ShapeCost    float64            // extra for cells holding a partial collider (lamp posts, curbs)
+
+	MaxCached int
+	cache     map[[2]image.Point][]image.Point
+	cacheKeys [][2]image.Point // oldest first
+}
+
+// NewPathfinder sets up a pathfinder with the default costs. roads may be nil.
+func NewPathfinder(grid *CollisionGrid, roads *RoadGraph) *Pathfinder {
+	return &Pathfinder{
+		Grid:        grid,
+		Roads:       roads,
+		RoadCost:    1,
+		DefaultCost: 2,
+		SurfaceCosts: map[string]float64{
+			"road":     1,
+			"sidewalk": 2,
+			"park":     3,
+		},
+		ShapeCost: 4,
+		MaxCached: 256,
+	}
+}
+
+// FindPath returns the route from one world-space point to another as cell centers
+// in world pixels, ending at the goal's cell. nil means there's no way through.
+func (p *Pathfinder) FindPath(fromX, fromY, toX, toY float64) []Point {
+	start, ok1 := p.openCell(fromX, fromY)
+	goal, ok2 := p.openCell(toX, toY)
+	if !ok1 || !ok2 {
+		return nil
+	}
+
+	key := [2]image.Point{start, goal}
+	cells, ok := p.cache[key]
+	if !ok {
+		cells = p.search(start, goal)
+		p.remember(key, cells)
+	}
+	if cells == nil {
+		return nil
+	}
+
+	path := make([]Point, len(cells))
+	for i, c := range cells {
+		path[i].X, path[i].Y = p.Grid.CellCenter(c.X, c.Y)
+	}
+	return path
+}
+
+// Invalidate forgets every cached path, e.g. after the grid changed
+func (p *Pathfinder) Invalidate() {
+	p.cache = nil
+	p.cacheKeys = nil
+}
+
+func (p *Pathfinder) remember(key [2]image.Point, cells []image.Point) {
+	if p.MaxCached <= 0 {
+		return
+	}
+	if p.cache == nil {
+		p.cache = map[[2]image.Point][]image.Point{}
+	}
+	if len(p.cacheKeys) >= p.MaxCached {
+		delete(p.cache, p.cacheKeys[0])
+		p.cacheKeys = p.cacheKeys[1:]
+	}
+	p.cache[key] = cells
+	p.cacheKeys = append(p.cacheKeys, key)
+}
+
+// cost is the price of entering a cell, negative if it's blocked
+func (p *Pathfinder) cost(cx, cy int) float64 {
+	g := p.Grid
+	if cx < 0 || cy < 0 || cx >= g.Width || cy >= g.Height || g.Solid[cy][cx] {
+		return -1
+	}
+
+	c := p.DefaultCost
+	if p.Roads != nil && p.Roads.IsRoad(cx+g.OriginX, cy+g.OriginY) {
+		c = p.RoadCost
+	} else if sc, ok := p.SurfaceCosts[g.Surface[cy][cx]]; ok {
+		c = sc
+	}
+	if len(g.cellShapes[cy*g.Width+cx]) > 0 {
+		c += p.ShapeCost
+	}
+	return c
+}
+
+// openCell finds the cell under a point, or the closest walkable one if that's blocked
+// (checkpoints tend to sit right against buildings)
+func (p *Pathfinder) openCell(x, y float64) (image.Point, bool) {
+	cx, cy, _ := p.Grid.CellAt(x, y)
+	if p.cost(cx, cy) >= 0 {
+		return image.Pt(cx, cy), true
+	}
+	for r := 1; r <= 3; r++ {
+		for dy := -r; dy <= r; dy++ {
+			for dx := -r; dx <= r; dx++ {
+				if max(abs(dx), abs(dy)) == r && p.cost(cx+dx, cy+dy) >= 0 {
+					return image.Pt(cx+dx, cy+dy), true
+				}
+			}
+		}
+	}
+	return image.Point{}, false
+}
+
+// cheapest is the lowest cell cost, so the heuristic never overestimates
+func (p *Pathfinder) cheapest() float64 {
+	c := math.Min(p.RoadCost, p.DefaultCost)
+	for _, sc := range p.SurfaceCosts {
+		c = math.Min(c, sc)
+	}
+	return math.Max(c, 0)
+}
+
+// search is plain A* over 8 neighbours. Diagonal steps can't cut blocked corners.
+func (p *Pathfinder) search(start, goal image.Point) []image.Point {
+	w := p.Grid.Width
+	idx := func(c image.Point) int { return c.Y*w + c.X }
+
+	minCost := p.cheapest()
+	h := func(c image.Point) float64 {
+		dx, dy := float64(abs(c.X-goal.X)), float64(abs(c.Y-goal.Y))
+		return minCost * (math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy))
+	}
+
+	gScore := map[int]float64{idx(start): 0}
+	cameFrom := map[int]image.Point{}
+	open := &cellHeap{{pt: start, f: h(start)}}
+
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(cellItem)
+		if cur.pt == goal {
+			path := []image.Point{goal}
+			for c := goal; c != start; {
+				c = cameFrom[idx(c)]
+				path = append(path, c)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		if cur.f > gScore[idx(cur.pt)]+h(cur.pt) {
+			continue // stale entry, a cheaper way here was found later
+		}
+
+		for _, d := range ring {
+			next := cur.pt.Add(d)
+			c := p.cost(next.X, next.Y)
+			if c < 0 {
+				continue
+			}
+			step := 1.0
+			if d.X != 0 && d.Y != 0 {
+				if p.cost(cur.pt.X+d.X, cur.pt.Y) < 0 || p.cost(cur.pt.X, cur.pt.Y+d.Y) < 0 {
+					continue
+				}
+				step = math.Sqrt2
+			}
+
+			g := gScore[idx(cur.pt)] + step*c
+			if old, seen := gScore[idx(next)]; seen && g >= old {
+				continue
+			}
+			gScore[idx(next)] = g
+			cameFrom[idx(next)] = cur.pt
+			heap.Push(open, cellItem{pt: next, f: g + h(next)})
+		}
+	}
+	return nil
+}
+
+type cellItem struct {
+	pt image.Point
+	f  float64
+}
+
+// cellHeap is the A* open list, lowest f first
+type cellHeap []cellItem
+
+func (q cellHeap) Len() int           { return len(q) }
+func (q cellHeap) Less(i, j int) bool { return q[i].f < q[j].f }
+func (q cellHeap) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *cellHeap) Push(x any)        { *q = append(*q, x.(cellItem)) }
+func (q *cellHeap) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,220 @@
+package tiled
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+// gridOf is a 10px collision grid at scale 1: 'X' is solid, 'p' is park, anything else sidewalk
+func gridOf(rows ...string) *CollisionGrid {
+	g := &CollisionGrid{Width: len(rows[0]), Height: len(rows), TileWidth: 10, TileHeight: 10, Scale: 1}
+	for _, r := range rows {
+		solid := make([]bool, len(r))
+		surface := make([]string, len(r))
+		for x, c := range r {
+			solid[x] = c == 'X'
+			if c == 'p' {
+				surface[x] = "park"
+			}
+		}
+		g.Solid = append(g.Solid, solid)
+		g.Surface = append(g.Surface, surface)
+	}
+	return g
+}
+
+// at is the world-space middle of cell (x, y)
+func at(x, y int) (float64, float64) { return float64(x*10 + 5), float64(y*10 + 5) }
+
+// cellsOf turns a path back into cells
+func cellsOf(path []Point) []image.Point {
+	var cells []image.Point
+	for _, p := range path {
+		cells = append(cells, image.Pt(int(p.X)/10, int(p.Y)/10))
+	}
+	return cells
+}
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name         string
+		rows         []string
+		from, to     image.Point
+		want         []image.Point // nil means no way through
+		wantLast     *image.Point  // only check where it ends
+		wantLen      int           // only check the length and the ends
+		mustNotVisit []image.Point
+	}{
+		{
+			name: "straight",
+			rows: []string{"....."},
+			from: image.Pt(0, 0), to: image.Pt(4, 0),
+			want: []image.Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}},
+		},
+		{
+			name: "same cell",
+			rows: []string{"..."},
+			from: image.Pt(1, 0), to: image.Pt(1, 0),
+			want: []image.Point{{1, 0}},
+		},
+		{
+			name: "through the gap",
+			rows: []string{
+				"..X..",
+				"..X..",
+				".....",
+			},
+			from: image.Pt(0, 0), to: image.Pt(4, 0),
+			wantLen:      7,
+			mustNotVisit: []image.Point{{2, 0}, {2, 1}},
+		},
+		{
+			// Diagonals can't squeeze between two blocked corners
+			name: "no corner cutting",
+			rows: []string{
+				".X.",
+				"X..",
+			},
+			from: image.Pt(0, 0), to: image.Pt(2, 0),
+			want: nil,
+		},
+		{
+			name: "walled in",
+			rows: []string{
+				".....",
+				".XXX.",
+				".X.X.",
+				".XXX.",
+			},
+			from: image.Pt(0, 0), to: image.Pt(2, 2),
+			want: nil,
+		},
+		{
+			name: "around the park",
+			rows: []string{
+				".ppp.",
+				".....",
+			},
+			from: image.Pt(0, 0), to: image.Pt(4, 0),
+			wantLen:      5,
+			mustNotVisit: []image.Point{{1, 0}, {2, 0}, {3, 0}},
+		},
+		{
+			// Checkpoints sit against buildings, a blocked goal snaps to the nearest open cell
+			name: "goal inside a wall",
+			rows: []string{
+				"...XX",
+				"...XX",
+			},
+			from: image.Pt(0, 0), to: image.Pt(4, 0),
+			wantLast: &image.Point{2, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPathfinder(gridOf(tt.rows...), nil)
+			fx, fy := at(tt.from.X, tt.from.Y)
+			tx, ty := at(tt.to.X, tt.to.Y)
+			got := cellsOf(p.FindPath(fx, fy, tx, ty))
+
+			switch {
+			case tt.wantLast != nil:
+				if len(got) == 0 || got[len(got)-1] != *tt.wantLast {
+					t.Errorf("path = %v, want it to end at %v", got, *tt.wantLast)
+				}
+			case tt.wantLen > 0:
+				if len(got) != tt.wantLen || got[0] != tt.from || got[len(got)-1] != tt.to {
+					t.Errorf("path = %v, want %d cells from %v to %v", got, tt.wantLen, tt.from, tt.to)
+				}
+			default:
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("path = %v, want %v", got, tt.want)
+				}
+			}
+			for _, c := range tt.mustNotVisit {
+				if slices.Contains(got, c) {
+					t.Errorf("path %v goes through %v", got, c)
+				}
+			}
+		})
+	}
+}
+
+func TestFindPathPrefersRoads(t *testing.T) {
+	grid := gridOf(
+		".....",
+		".....",
+	)
+	m := roadMap("......", "######")
+	m.TileWidth, m.TileHeight = 10, 10
+	p := NewPathfinder(grid, buildRoads(m))
+
+	fx, fy := at(0, 0)
+	tx, ty := at(4, 0)
+	got := cellsOf(p.FindPath(fx, fy, tx, ty))
+	for _, c := range got[1 : len(got)-1] {
+		if c.Y != 1 {
+			t.Errorf("path %v stays on the sidewalk instead of the road below", got)
+			break
+		}
+	}
+}
+
+func TestPathfinderCache(t *testing.T) {
+	p := NewPathfinder(gridOf(
+		".....",
+		".XXX.",
+		".X.X.",
+		".XXX.",
+	), nil)
+	p.MaxCached = 2
+
+	find := func(x0, y0, x1, y1 int) []Point {
+		fx, fy := at(x0, y0)
+		tx, ty := at(x1, y1)
+		return p.FindPath(fx, fy, tx, ty)
+	}
+
+	// Unreachable goals are remembered too, so rivals don't search again every tick
+	if find(0, 0, 2, 2) != nil {
+		t.Fatal("the middle is walled in")
+	}
+	unreachable := [2]image.Point{{0, 0}, {2, 2}}
+	if cells, ok := p.cache[unreachable]; !ok || cells != nil {
+		t.Errorf("cache has %v, %v for the walled in goal", cells, ok)
+	}
+
+	find(0, 0, 4, 0)
+	find(0, 0, 4, 3)
+	if len(p.cache) != 2 || len(p.cacheKeys) != 2 {
+		t.Fatalf("cache holds %d paths, %d keys, want 2", len(p.cache), len(p.cacheKeys))
+	}
+	if _, ok := p.cache[unreachable]; ok {
+		t.Error("the oldest path should have been dropped")
+	}
+
+	// A cached path comes back the same
+	if a, b := find(0, 0, 4, 3), find(0, 0, 4, 3); !slices.Equal(a, b) || len(a) == 0 {
+		t.Errorf("cached path %v, then %v", a, b)
+	}
+
+	p.Invalidate()
+	if len(p.cache) != 0 || len(p.cacheKeys) != 0 {
+		t.Error("Invalidate left paths behind")
+	}
+
+	p.MaxCached = 0
+	find(0, 0, 4, 0)
+	if len(p.cache) != 0 {
+		t.Error("MaxCached 0 should turn the cache off")
+	}
+}
+
+func TestFindPathOffTheMap(t *testing.T) {
+	p := NewPathfinder(gridOf("...", "..."), nil)
+	if got := p.FindPath(-500, -500, 15, 5); got != nil {
+		t.Errorf("got %v from far off the map", got)
+	}
+}
@@ -0,0 +1,184 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Properties is the custom property list Tiled attaches to maps, layers, tilesets, tiles and objects.
+//
+// Values are stored the way encoding/json decodes them (numbers are float64, object
+// references are the object id as float64), except class properties: their members
+// are decoded into a nested Properties.
+type Properties []ObjectProperty
+
+// PropertyValue is every Go type a property can be read as
+type PropertyValue interface {
+	string | int | float64 | bool | color.RGBA | Properties
+}
+
+// Property reads a named property as T, or returns fallback if it's missing
+// or isn't something a T can hold (a bool read as an int, "abc" read as a color...).
+// Ints and floats convert into each other, "#AARRGGBB" strings parse into color.RGBA.
+func Property[T PropertyValue](props Properties, name string, fallback T) T {
+	v, ok := findProperty(props, name)
+	if !ok {
+		return fallback
+	}
+
+	var out T
+	switch p := any(&out).(type) {
+	case *string:
+		s, ok := v.(string)
+		if !ok {
+			return fallback
+		}
+		*p = s
+	case *int:
+		f, ok := v.(float64)
+		if !ok {
+			return fallback
+		}
+		*p = int(f)
+	case *float64:
+		f, ok := v.(float64)
+		if !ok {
+			return fallback
+		}
+		*p = f
+	case *bool:
+		b, ok := v.(bool)
+		if !ok {
+			return fallback
+		}
+		*p = b
+	case *color.RGBA:
+		s, ok := v.(string)
+		if !ok {
+			return fallback
+		}
+		c, err := ParseColor(s)
+		if err != nil {
+			return fallback
+		}
+		*p = c
+	case *Properties:
+		members, ok := v.(Properties)
+		if !ok {
+			return fallback
+		}
+		*p = members
+	}
+	return out
+}
+
+// String returns a string property (also "file" and "color" ones, unparsed).
+// Numbers and bools are formatted, so a direction typed in as an int still comes
+// through; class properties give the fallback.
+func (p Properties) String(name, fallback string) string {
+	v, ok := findProperty(p, name)
+	if !ok {
+		return fallback
+	}
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	return fallback
+}
+
+// Int returns an int property, floats are truncated
+func (p Properties) Int(name string, fallback int) int { return Property(p, name, fallback) }
+
+// Float returns a float property, ints work too
+func (p Properties) Float(name string, fallback float64) float64 { return Property(p, name, fallback) }
+
+// Bool returns a bool property
+func (p Properties) Bool(name string, fallback bool) bool { return Property(p, name, fallback) }
+
+// Color returns a color property
+func (p Properties) Color(name string, fallback color.RGBA) color.RGBA {
+	return Property(p, name, fallback)
+}
+
+// File returns a file property. Tiled stores it relative to the file the property is in.
+func (p Properties) File(name, fallback string) string { return Property(p, name, fallback) }
+
+// ObjectRef returns the id an object property points at, 0 if it's unset.
+// Look the object up with Map.ObjectByID.
+func (p Properties) ObjectRef(name string) int { return Property(p, name, 0) }
+
+// Class returns the members of a class-typed property (nil if it's missing).
+// Only members the designer changed are stored, the rest keep their project defaults.
+func (p Properties) Class(name string) Properties { return Property[Properties](p, name, nil) }
+
+// Has reports whether a property is set at all
+func (p Properties) Has(name string) bool {
+	_, ok := findProperty(p, name)
+	return ok
+}
+
+// ParseColor reads a Tiled color, "#AARRGGBB" or "#RRGGBB" (alpha 255)
+func ParseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.RGBA{}, fmt.Errorf("tiled: bad color %q", s)
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("tiled: bad color %q", s)
+	}
+	if len(hex) == 6 {
+		n |= 0xff000000
+	}
+	return color.RGBA{R: uint8(n >> 16), G: uint8(n >> 8), B: uint8(n), A: uint8(n >> 24)}, nil
+}
+
+// UnmarshalJSON decodes class properties into nested Properties, the rest is left as is
+func (op *ObjectProperty) UnmarshalJSON(b []byte) error {
+	type plain ObjectProperty // no UnmarshalJSON, so no recursion
+	var p plain
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*op = ObjectProperty(p)
+
+	if op.Type == "class" {
+		members, _ := op.Value.(map[string]interface{})
+		op.Value = classMembers(members)
+	}
+	return nil
+}
+
+// classMembers turns a JSON class value into Properties. JSON doesn't say what type each
+// member is, so it's guessed from the value (sorted by name to keep the order stable).
+func classMembers(m map[string]interface{}) Properties {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make(Properties, 0, len(names))
+	for _, name := range names {
+		v := m[name]
+		typ := "string"
+		switch val := v.(type) {
+		case float64:
+			typ = "float"
+		case bool:
+			typ = "bool"
+		case map[string]interface{}:
+			typ = "class"
+			v = classMembers(val)
+		}
+		out = append(out, ObjectProperty{Name: name, Type: typ, Value: v})
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package tiled
+
+import "testing"
+
+func TestPropertiesString(t *testing.T) {
+	props := Properties{
+		{Name: "location", Type: "string", Value: "Pier 17"},
+		{Name: "lane", Type: "int", Value: 2.0},
+		{Name: "speed", Type: "float", Value: 1.5},
+		{Name: "oneway", Type: "bool", Value: true},
+		{Name: "spawn", Type: "class", Value: Properties{}},
+	}
+	tests := []struct{ name, want string }{
+		{"location", "Pier 17"},
+		{"lane", "2"},
+		{"speed", "1.5"},
+		{"oneway", "true"},
+		{"spawn", "fallback"},
+		{"missing", "fallback"},
+	}
+	o := &Object{Properties: props}
+	for _, tt := range tests {
+		if got := props.String(tt.name, "fallback"); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := o.GetStringProperty(tt.name, "fallback"); got != tt.want {
+			t.Errorf("GetStringProperty(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
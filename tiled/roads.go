@@ -0,0 +1,560 @@
+package tiled
+
+import (
+	"image"
+	"math"
+)
+
+// ObjectOneWay marks a one-way street: a shape on any object layer with this class
+// and a "direction" property (UP, DOWN, LEFT, RIGHT). Every street segment whose
+// middle is inside the shape only runs that way.
+const ObjectOneWay = "oneway"
+
+// spurLength is how short (in tiles) a dead end has to be to count as a thinning
+// artifact. Real dead ends are longer than half a street is wide.
+const spurLength = 4
+
+// RoadGraph is the street network of a road layer: intersections and dead ends
+// (nodes) joined by street segments (edges).
+//
+// It's built once at load time by thinning the road tiles down to one-tile-wide
+// centerlines and following them, so it doesn't care how wide a street is drawn.
+type RoadGraph struct {
+	Nodes []RoadNode
+	Edges []RoadEdge
+
+	TileWidth  int
+	TileHeight int
+
+	bounds image.Rectangle // tiles, same as the map's
+	road   []bool          // road mask over bounds
+}
+
+// RoadNode is an intersection or a dead end, at tile (X, Y) on the centerline
+type RoadNode struct {
+	ID    int
+	X, Y  int
+	Edges []int // ids of the edges touching this node
+}
+
+// RoadEdge is one street segment between two nodes
+type RoadEdge struct {
+	ID       int
+	From, To int
+	Path     []image.Point // centerline tiles, From's tile first and To's tile last
+	Length   float64       // map pixels along Path
+	Width    int           // tiles across the road halfway along, roughly the number of lanes
+	OneWay   bool          // traffic only goes From -> To
+}
+
+// BuildRoadGraph analyzes a tile layer. isRoad gets every GID with the flip bits
+// already removed, e.g. func(gid uint32) bool { return gid == 2 }.
+func BuildRoadGraph(m *Map, layer *Layer, isRoad func(gid uint32) bool) *RoadGraph {
+	b := m.Bounds()
+	g := &RoadGraph{
+		TileWidth:  m.TileWidth,
+		TileHeight: m.TileHeight,
+		bounds:     b,
+		road:       make([]bool, b.Dx()*b.Dy()),
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			gid := m.TileAt(layer, x, y) &^ flipMask
+			g.road[g.index(x, y)] = gid != 0 && isRoad(gid)
+		}
+	}
+
+	skel := thin(g.road, b.Dx(), b.Dy())
+	g.trace(skel)
+	g.prune()
+	g.applyOneWays(m)
+	return g
+}
+
+func (g *RoadGraph) index(x, y int) int {
+	return (y-g.bounds.Min.Y)*g.bounds.Dx() + (x - g.bounds.Min.X)
+}
+
+// IsRoad reports whether map tile (x, y) is road
+func (g *RoadGraph) IsRoad(x, y int) bool {
+	if !image.Pt(x, y).In(g.bounds) {
+		return false
+	}
+	return g.road[g.index(x, y)]
+}
+
+// IsRoadAt is IsRoad for a point in map pixels
+func (g *RoadGraph) IsRoadAt(px, py float64) bool {
+	return g.IsRoad(int(math.Floor(px/float64(g.TileWidth))), int(math.Floor(py/float64(g.TileHeight))))
+}
+
+// NodePos is the center of a node's tile in map pixels
+func (g *RoadGraph) NodePos(id int) (float64, float64) {
+	n := &g.Nodes[id]
+	return (float64(n.X) + 0.5) * float64(g.TileWidth), (float64(n.Y) + 0.5) * float64(g.TileHeight)
+}
+
+// NearestNode returns the node closest to a point in map pixels, -1 if the graph is empty
+func (g *RoadGraph) NearestNode(px, py float64) int {
+	best, bestDist := -1, math.Inf(1)
+	for i := range g.Nodes {
+		nx, ny := g.NodePos(i)
+		if d := math.Hypot(nx-px, ny-py); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// Other returns the node at the far end of an edge, seen from node
+func (e *RoadEdge) Other(node int) int {
+	if e.From == node {
+		return e.To
+	}
+	return e.From
+}
+
+// CanTravel reports whether the edge may be driven starting at node
+func (e *RoadEdge) CanTravel(from int) bool {
+	return !e.OneWay || e.From == from
+}
+
+// Direction is the main way the edge runs from From to To: "UP", "DOWN", "LEFT" or "RIGHT"
+// (same names as taxi spawn directions)
+func (e *RoadEdge) Direction() string {
+	a, z := e.Path[0], e.Path[len(e.Path)-1]
+	dx, dy := z.X-a.X, z.Y-a.Y
+	if abs(dx) >= abs(dy) {
+		if dx < 0 {
+			return "LEFT"
+		}
+		return "RIGHT"
+	}
+	if dy < 0 {
+		return "UP"
+	}
+	return "DOWN"
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// --- Thinning ---
+
+// 8 neighbours clockwise from north: N, NE, E, SE, S, SW, W, NW
+var ring = [8]image.Point{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
+
+// thin is Zhang-Suen thinning: peels road tiles off the edges until only
+// one-tile-wide centerlines are left. Works on a w*h mask, outside counts as empty.
+func thin(mask []bool, w, h int) []bool {
+	skel := append([]bool(nil), mask...)
+	at := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h && skel[y*w+x]
+	}
+
+	var remove []int
+	for changed := true; changed; {
+		changed = false
+		for step := 0; step < 2; step++ {
+			remove = remove[:0]
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					if !skel[y*w+x] {
+						continue
+					}
+
+					var p [8]bool
+					count := 0
+					for i, d := range ring {
+						p[i] = at(x+d.X, y+d.Y)
+						if p[i] {
+							count++
+						}
+					}
+					if count < 2 || count > 6 || transitions(p) != 1 {
+						continue
+					}
+
+					n, e, s, wst := p[0], p[2], p[4], p[6]
+					if step == 0 && (n && e && s || e && s && wst) {
+						continue
+					}
+					if step == 1 && (n && e && wst || n && s && wst) {
+						continue
+					}
+					remove = append(remove, y*w+x)
+				}
+			}
+
+			for _, i := range remove {
+				skel[i] = false
+			}
+			if len(remove) > 0 {
+				changed = true
+			}
+		}
+	}
+	return skel
+}
+
+// transitions counts empty -> filled steps going once around the neighbours.
+// 1 is the end of a line, 2 the middle of one, 3+ a junction.
+func transitions(p [8]bool) int {
+	n := 0
+	for i := range p {
+		if !p[i] && p[(i+1)%8] {
+			n++
+		}
+	}
+	return n
+}
+
+// --- Tracing ---
+
+// trace turns the skeleton into nodes and edges. Junction tiles next to each other
+// become one node, everything between nodes is followed into an edge.
+func (g *RoadGraph) trace(skel []bool) {
+	b := g.bounds
+	w, h := b.Dx(), b.Dy()
+	on := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h && skel[y*w+x]
+	}
+
+	// Which skeleton tiles are nodes (endpoints or junctions)
+	nodeOf := make([]int, w*h)
+	for i := range nodeOf {
+		nodeOf[i] = -1
+	}
+	isNode := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !skel[y*w+x] {
+				continue
+			}
+			var p [8]bool
+			for i, d := range ring {
+				p[i] = on(x+d.X, y+d.Y)
+			}
+			if t := transitions(p); t != 2 {
+				isNode[y*w+x] = true
+			}
+		}
+	}
+
+	// Group touching node tiles into one node, placed on the tile nearest their middle
+	for start := range isNode {
+		if !isNode[start] || nodeOf[start] >= 0 {
+			continue
+		}
+
+		id := len(g.Nodes)
+		group := []int{start}
+		nodeOf[start] = id
+		sumX, sumY := 0, 0
+		for k := 0; k < len(group); k++ {
+			x, y := group[k]%w, group[k]/w
+			sumX, sumY = sumX+x, sumY+y
+			for _, d := range ring {
+				nx, ny := x+d.X, y+d.Y
+				if on(nx, ny) && isNode[ny*w+nx] && nodeOf[ny*w+nx] < 0 {
+					nodeOf[ny*w+nx] = id
+					group = append(group, ny*w+nx)
+				}
+			}
+		}
+
+		cx, cy := float64(sumX)/float64(len(group)), float64(sumY)/float64(len(group))
+		best, bestDist := group[0], math.Inf(1)
+		for _, i := range group {
+			if d := math.Hypot(float64(i%w)-cx, float64(i/w)-cy); d < bestDist {
+				best, bestDist = i, d
+			}
+		}
+		g.Nodes = append(g.Nodes, RoadNode{ID: id, X: best%w + b.Min.X, Y: best/w + b.Min.Y})
+	}
+
+	// Follow every line leaving every node tile
+	visited := make([]bool, w*h)
+	linked := map[[2]int]bool{} // node tiles from different nodes that touch directly
+	for start := range isNode {
+		if !isNode[start] {
+			continue
+		}
+		from := nodeOf[start]
+		sx, sy := start%w, start/w
+
+		for _, d := range ring {
+			nx, ny := sx+d.X, sy+d.Y
+			if !on(nx, ny) {
+				continue
+			}
+			next := ny*w + nx
+
+			if isNode[next] {
+				to := nodeOf[next]
+				key := [2]int{min(start, next), max(start, next)}
+				if to != from && !linked[key] {
+					linked[key] = true
+					g.addEdge(from, to, []int{start, next}, w)
+				}
+				continue
+			}
+			if visited[next] {
+				continue
+			}
+
+			path := []int{start, next}
+			visited[next] = true
+			to := -1
+			for to < 0 {
+				cur := path[len(path)-1]
+				cx, cy := cur%w, cur/w
+				step := -1
+
+				// A node ends the line (the start node only once we've moved away from it)
+				for _, dd := range ring {
+					px, py := cx+dd.X, cy+dd.Y
+					if !on(px, py) {
+						continue
+					}
+					i := py*w + px
+					if isNode[i] && i != start && (nodeOf[i] != from || len(path) > 2) {
+						to, step = nodeOf[i], i
+						break
+					}
+				}
+				if step < 0 {
+					// Orthogonal neighbours first, so diagonal staircases don't skip tiles
+					for _, k := range [8]int{0, 2, 4, 6, 1, 3, 5, 7} {
+						px, py := cx+ring[k].X, cy+ring[k].Y
+						if !on(px, py) {
+							continue
+						}
+						if i := py*w + px; !isNode[i] && !visited[i] {
+							step = i
+							break
+						}
+					}
+					if step < 0 {
+						break // ran out, only happens on broken skeletons
+					}
+					visited[step] = true
+				}
+				path = append(path, step)
+			}
+
+			if to >= 0 {
+				g.addEdge(from, to, path, w)
+			}
+		}
+	}
+}
+
+// addEdge records an edge along skeleton tiles (indexes into a w-wide mask)
+func (g *RoadGraph) addEdge(from, to int, tiles []int, w int) {
+	b := g.bounds
+	e := RoadEdge{ID: len(g.Edges), From: from, To: to}
+
+	for _, i := range tiles {
+		e.Path = append(e.Path, image.Pt(i%w+b.Min.X, i/w+b.Min.Y))
+	}
+	e.Length = pathLength(e.Path, g.TileWidth, g.TileHeight)
+	e.Width = g.widthAt(e.Path[len(e.Path)/2], e.Direction())
+
+	g.Edges = append(g.Edges, e)
+	g.Nodes[from].Edges = append(g.Nodes[from].Edges, e.ID)
+	if to != from {
+		g.Nodes[to].Edges = append(g.Nodes[to].Edges, e.ID)
+	}
+}
+
+func pathLength(path []image.Point, tw, th int) float64 {
+	total := 0.0
+	for i := 1; i < len(path); i++ {
+		total += math.Hypot(float64((path[i].X-path[i-1].X)*tw), float64((path[i].Y-path[i-1].Y)*th))
+	}
+	return total
+}
+
+// widthAt measures the road straight across a street running in dir
+func (g *RoadGraph) widthAt(p image.Point, dir string) int {
+	dx, dy := 0, 1
+	if dir == "UP" || dir == "DOWN" {
+		dx, dy = 1, 0
+	}
+	n := 1
+	for x, y := p.X+dx, p.Y+dy; g.IsRoad(x, y); x, y = x+dx, y+dy {
+		n++
+	}
+	for x, y := p.X-dx, p.Y-dy; g.IsRoad(x, y); x, y = x-dx, y-dy {
+		n++
+	}
+	return n
+}
+
+// --- Cleanup ---
+
+// prune drops the short dead ends thinning leaves at corners, joins the segments that
+// meet at what's left of those junctions, and renumbers everything.
+func (g *RoadGraph) prune() {
+	dead := make([]bool, len(g.Edges))
+	degree := func(n int) int {
+		c := 0
+		for _, e := range g.Nodes[n].Edges {
+			if !dead[e] {
+				c++
+			}
+		}
+		return c
+	}
+
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		if len(e.Path) > spurLength || e.From == e.To {
+			continue
+		}
+		// Only a spur if it hangs off something bigger
+		if degree(e.From) == 1 && degree(e.To) > 2 || degree(e.To) == 1 && degree(e.From) > 2 {
+			dead[i] = true
+		}
+	}
+
+	// Join the two edges through every node that's now just a bend in the road
+	for n := range g.Nodes {
+		var live []int
+		for _, e := range g.Nodes[n].Edges {
+			if !dead[e] {
+				live = append(live, e)
+			}
+		}
+		if len(live) != 2 || live[0] == live[1] {
+			continue
+		}
+
+		a, c := &g.Edges[live[0]], &g.Edges[live[1]]
+		if a.From == a.To || c.From == c.To {
+			continue
+		}
+		if a.From == n {
+			a.reverse()
+		}
+		if c.To == n {
+			c.reverse()
+		}
+
+		// a now ends at n, c starts there
+		a.Path = append(a.Path, c.Path[1:]...)
+		a.To = c.To
+		a.Length += c.Length
+		a.Width = max(a.Width, c.Width)
+		dead[c.ID] = true
+
+		far := &g.Nodes[c.To]
+		for k, id := range far.Edges {
+			if id == c.ID {
+				far.Edges[k] = a.ID
+			}
+		}
+		g.Nodes[n].Edges = nil
+	}
+
+	g.compact(dead)
+}
+
+func (e *RoadEdge) reverse() {
+	for i, j := 0, len(e.Path)-1; i < j; i, j = i+1, j-1 {
+		e.Path[i], e.Path[j] = e.Path[j], e.Path[i]
+	}
+	e.From, e.To = e.To, e.From
+}
+
+// compact drops dead edges and nodes without edges, and renumbers the rest
+func (g *RoadGraph) compact(dead []bool) {
+	edgeID := make([]int, len(g.Edges))
+	var edges []RoadEdge
+	for i, e := range g.Edges {
+		edgeID[i] = -1
+		if dead[i] {
+			continue
+		}
+		edgeID[i] = len(edges)
+		edges = append(edges, e)
+	}
+
+	nodeID := make([]int, len(g.Nodes))
+	var nodes []RoadNode
+	for i, n := range g.Nodes {
+		nodeID[i] = -1
+		var kept []int
+		for _, e := range n.Edges {
+			if edgeID[e] >= 0 {
+				kept = append(kept, edgeID[e])
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		nodeID[i] = len(nodes)
+		n.ID, n.Edges = len(nodes), kept
+		nodes = append(nodes, n)
+	}
+
+	for i := range edges {
+		edges[i].ID = i
+		edges[i].From = nodeID[edges[i].From]
+		edges[i].To = nodeID[edges[i].To]
+	}
+	g.Nodes, g.Edges = nodes, edges
+}
+
+// applyOneWays flips and locks the edges inside "oneway" shapes. A shape without a
+// direction (UP, DOWN, LEFT or RIGHT) is skipped, and so is any edge it covers that
+// runs across that direction: there's no telling which way traffic should go.
+func (g *RoadGraph) applyOneWays(m *Map) {
+	walkObjects(m.Layers, func(_ *Layer, obj *Object) {
+		if obj.Type != ObjectOneWay {
+			return
+		}
+		shape, ok := ObjectShape(obj)
+		if !ok {
+			return
+		}
+		dir := obj.Properties.String("direction", "")
+		if oppositeDirection(dir) == "" {
+			return
+		}
+
+		for i := range g.Edges {
+			e := &g.Edges[i]
+			mid := e.Path[len(e.Path)/2]
+			mx := (float64(mid.X) + 0.5) * float64(g.TileWidth)
+			my := (float64(mid.Y) + 0.5) * float64(g.TileHeight)
+			if !shape.Contains(mx, my) {
+				continue
+			}
+			if oppositeDirection(e.Direction()) == dir {
+				e.reverse()
+			}
+			e.OneWay = e.Direction() == dir
+		}
+	})
+}
+
+func oppositeDirection(dir string) string {
+	switch dir {
+	case "UP":
+		return "DOWN"
+	case "DOWN":
+		return "UP"
+	case "LEFT":
+		return "RIGHT"
+	case "RIGHT":
+		return "LEFT"
+	}
+	return ""
+}
@@ -0,0 +1,233 @@
+package tiled
+
+import (
+	"image"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// roadMap is a 16px map with one "Roads" tile layer, '#' is road (gid 1)
+func roadMap(rows ...string) *Map {
+	m := &Map{Width: len(rows[0]), Height: len(rows), TileWidth: 16, TileHeight: 16}
+	layer := Layer{Name: "Roads", Type: "tilelayer", Visible: true}
+	for _, r := range rows {
+		for _, c := range r {
+			gid := uint32(0)
+			if c == '#' {
+				gid = 1
+			}
+			layer.Data = append(layer.Data, gid)
+		}
+	}
+	m.Layers = []Layer{layer}
+	return m
+}
+
+func buildRoads(m *Map) *RoadGraph {
+	return BuildRoadGraph(m, &m.Layers[0], func(gid uint32) bool { return gid == 1 })
+}
+
+// crossing draws a size x size map with 3-wide roads through the middle, across and/or down
+func crossing(size int, across, down bool) []string {
+	mid := size / 2
+	rows := make([]string, size)
+	for y := range rows {
+		var b strings.Builder
+		for x := 0; x < size; x++ {
+			if across && abs(y-mid) <= 1 || down && abs(x-mid) <= 1 {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		rows[y] = b.String()
+	}
+	return rows
+}
+
+func TestBuildRoadGraph(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []string
+		degrees []int // every node's edge count, sorted
+		width   int   // of every edge
+	}{
+		{"empty", []string{"....", "...."}, nil, 0},
+		{"one lane", []string{"..........", "#########.", ".........."}, []int{1, 1}, 1},
+		{"three lanes", crossing(11, true, false), []int{1, 1}, 3},
+		{"crossroads", crossing(21, true, true), []int{1, 1, 1, 1, 4}, 3},
+		{
+			// Thinning leaves stubs in a thick corner, they're pruned and the bend joined up
+			"thick corner",
+			[]string{"###.......", "###.......", "###.......", "###.......", "##########", "##########", "##########"},
+			[]int{1, 1}, 0,
+		},
+		{
+			// Arms shorter than spurLength look just like thinning stubs, so they go too
+			"stubby crossroads",
+			[]string{"....###....", "....###....", "....###....", "###########", "###########", "###########", "....###....", "....###....", "....###...."},
+			[]int{1, 1}, 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := buildRoads(roadMap(tt.rows...))
+
+			var degrees []int
+			for i, n := range g.Nodes {
+				if n.ID != i {
+					t.Errorf("node %d has id %d", i, n.ID)
+				}
+				degrees = append(degrees, len(n.Edges))
+			}
+			slices.Sort(degrees)
+			if !slices.Equal(degrees, tt.degrees) {
+				t.Errorf("node degrees = %v, want %v", degrees, tt.degrees)
+			}
+
+			for i, e := range g.Edges {
+				if e.ID != i {
+					t.Errorf("edge %d has id %d", i, e.ID)
+				}
+				// Paths run node to node along touching tiles
+				from, to := g.Nodes[e.From], g.Nodes[e.To]
+				if e.Path[0] != image.Pt(from.X, from.Y) || e.Path[len(e.Path)-1] != image.Pt(to.X, to.Y) {
+					t.Errorf("edge %d runs %v..%v, nodes are at %d,%d and %d,%d", i, e.Path[0], e.Path[len(e.Path)-1], from.X, from.Y, to.X, to.Y)
+				}
+				for k := 1; k < len(e.Path); k++ {
+					if d := e.Path[k].Sub(e.Path[k-1]); abs(d.X) > 1 || abs(d.Y) > 1 {
+						t.Errorf("edge %d jumps from %v to %v", i, e.Path[k-1], e.Path[k])
+					}
+				}
+				if tt.width > 0 && e.Width != tt.width {
+					t.Errorf("edge %d is %d wide, want %d", i, e.Width, tt.width)
+				}
+			}
+		})
+	}
+}
+
+func TestRoadGraphLengthAndLookups(t *testing.T) {
+	g := buildRoads(roadMap("..........", "#########.", ".........."))
+	if len(g.Edges) != 1 {
+		t.Fatalf("got %d edges, want 1", len(g.Edges))
+	}
+	if e := g.Edges[0]; e.Length != 8*16 {
+		t.Errorf("length = %v, want %v", e.Length, 8*16)
+	}
+
+	if !g.IsRoadAt(20, 20) || g.IsRoadAt(20, 40) || g.IsRoadAt(-1, 20) {
+		t.Error("IsRoadAt disagrees with the map")
+	}
+	if n := g.NearestNode(130, 20); g.Nodes[n].X != 8 {
+		t.Errorf("nearest node to the east end is at x=%d", g.Nodes[n].X)
+	}
+	if (&RoadGraph{}).NearestNode(0, 0) != -1 {
+		t.Error("an empty graph has no nearest node")
+	}
+}
+
+// prune works on whatever trace found, so feed it a junction by hand:
+// a long street A-J-B with a 3-tile stub J-S hanging off J
+func TestRoadGraphPrune(t *testing.T) {
+	line := func(x0, y0, x1, y1 int) []image.Point {
+		var p []image.Point
+		for x, y := x0, y0; ; {
+			p = append(p, image.Pt(x, y))
+			if x == x1 && y == y1 {
+				return p
+			}
+			x, y = x+sign(x1-x), y+sign(y1-y)
+		}
+	}
+	g := &RoadGraph{
+		TileWidth: 16, TileHeight: 16,
+		Nodes: []RoadNode{
+			{ID: 0, X: 0, Y: 5, Edges: []int{0}},        // A
+			{ID: 1, X: 10, Y: 5, Edges: []int{0, 1, 2}}, // J
+			{ID: 2, X: 20, Y: 5, Edges: []int{1}},       // B
+			{ID: 3, X: 10, Y: 7, Edges: []int{2}},       // S
+		},
+		Edges: []RoadEdge{
+			{ID: 0, From: 0, To: 1, Path: line(0, 5, 10, 5), Length: 160, Width: 3},
+			{ID: 1, From: 2, To: 1, Path: line(20, 5, 10, 5), Length: 160, Width: 2}, // drawn backwards
+			{ID: 2, From: 1, To: 3, Path: line(10, 5, 10, 7), Length: 32, Width: 1},
+		},
+	}
+	g.prune()
+
+	if len(g.Nodes) != 2 || len(g.Edges) != 1 {
+		t.Fatalf("got %d nodes, %d edges, want A and B joined by one street", len(g.Nodes), len(g.Edges))
+	}
+	e := g.Edges[0]
+	if want := line(0, 5, 20, 5); !slices.Equal(e.Path, want) {
+		t.Errorf("path = %v, want %v", e.Path, want)
+	}
+	if e.Length != 320 || e.Width != 3 {
+		t.Errorf("length %v width %d, want 320 and the wider of the two", e.Length, e.Width)
+	}
+	if g.Nodes[e.From].X != 0 || g.Nodes[e.To].X != 20 {
+		t.Errorf("edge runs node at x=%d to x=%d", g.Nodes[e.From].X, g.Nodes[e.To].X)
+	}
+	for _, n := range g.Nodes {
+		if !slices.Equal(n.Edges, []int{0}) {
+			t.Errorf("node %d edges = %v", n.ID, n.Edges)
+		}
+	}
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestRoadGraphOneWay(t *testing.T) {
+	tests := []struct {
+		name   string
+		across bool // the road runs across, else down
+		props  Properties
+		want   string // the one-way's direction, "" if it should stay two-way
+	}{
+		{"left", true, Properties{{Name: "direction", Type: "string", Value: "LEFT"}}, "LEFT"},
+		{"right", true, Properties{{Name: "direction", Type: "string", Value: "RIGHT"}}, "RIGHT"},
+		{"up", false, Properties{{Name: "direction", Type: "string", Value: "UP"}}, "UP"},
+		{"no direction", true, nil, ""},
+		{"empty direction", true, Properties{{Name: "direction", Type: "string", Value: ""}}, ""},
+		{"made up direction", true, Properties{{Name: "direction", Type: "string", Value: "NORTH"}}, ""},
+		{"lower case", true, Properties{{Name: "direction", Type: "string", Value: "left"}}, ""},
+		{"across the road", true, Properties{{Name: "direction", Type: "string", Value: "UP"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := roadMap(crossing(11, tt.across, !tt.across)...)
+			m.Layers = append(m.Layers, Layer{Name: "Traffic", Type: "objectgroup", Visible: true, Objects: []Object{{
+				Type: ObjectOneWay, X: 0, Y: 0, Width: 176, Height: 176, Properties: tt.props,
+			}}})
+			g := buildRoads(m)
+
+			if len(g.Edges) != 1 {
+				t.Fatalf("got %d edges, want 1", len(g.Edges))
+			}
+			e := g.Edges[0]
+			if tt.want == "" {
+				if e.OneWay || !e.CanTravel(e.From) || !e.CanTravel(e.To) {
+					t.Errorf("oneway %v, want a two-way street", e.OneWay)
+				}
+				return
+			}
+			if !e.OneWay || e.Direction() != tt.want {
+				t.Errorf("oneway %v direction %s, want a one-way heading %s", e.OneWay, e.Direction(), tt.want)
+			}
+			if !e.CanTravel(e.From) || e.CanTravel(e.To) {
+				t.Error("a one-way only runs From -> To")
+			}
+		})
+	}
+}
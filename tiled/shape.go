@@ -0,0 +1,139 @@
+package tiled
+
+import "math"
+
+// Shape is a closed polygon in map pixels. Rectangles and ellipses from Tiled
+// are turned into polygons too, so there's only one kind of overlap test.
+type Shape struct {
+	Points                 []Point
+	MinX, MinY, MaxX, MaxY float64 // bounding box
+}
+
+// ellipseSegments is how round an ellipse collider is
+const ellipseSegments = 16
+
+// NewShape builds a Shape from points and works out the bounding box
+func NewShape(points []Point) Shape {
+	s := Shape{Points: points}
+	if len(points) == 0 {
+		return s
+	}
+	s.MinX, s.MinY = points[0].X, points[0].Y
+	s.MaxX, s.MaxY = points[0].X, points[0].Y
+	for _, p := range points[1:] {
+		s.MinX = math.Min(s.MinX, p.X)
+		s.MinY = math.Min(s.MinY, p.Y)
+		s.MaxX = math.Max(s.MaxX, p.X)
+		s.MaxY = math.Max(s.MaxY, p.Y)
+	}
+	return s
+}
+
+// ObjectShape turns a rectangle, ellipse or polygon object into a Shape in map pixels.
+// Points and zero-sized rectangles have no area, so ok is false for them.
+func ObjectShape(o *Object) (Shape, bool) {
+	var local []Point
+
+	switch {
+	case o.Point:
+		return Shape{}, false
+	case len(o.Polygon) >= 3:
+		local = o.Polygon
+	case o.Width <= 0 || o.Height <= 0:
+		return Shape{}, false
+	case o.Ellipse:
+		rx, ry := o.Width/2, o.Height/2
+		for i := 0; i < ellipseSegments; i++ {
+			a := 2 * math.Pi * float64(i) / ellipseSegments
+			local = append(local, Point{X: rx + rx*math.Cos(a), Y: ry + ry*math.Sin(a)})
+		}
+	default:
+		local = []Point{{0, 0}, {o.Width, 0}, {o.Width, o.Height}, {0, o.Height}}
+	}
+
+	// Tiled rotates around the object's origin (top-left for rectangles)
+	sin, cos := math.Sincos(o.Rotation * math.Pi / 180)
+	points := make([]Point, len(local))
+	for i, p := range local {
+		points[i] = Point{
+			X: o.X + p.X*cos - p.Y*sin,
+			Y: o.Y + p.X*sin + p.Y*cos,
+		}
+	}
+	return NewShape(points), true
+}
+
+// Translate returns a copy moved by (dx, dy)
+func (s Shape) Translate(dx, dy float64) Shape {
+	points := make([]Point, len(s.Points))
+	for i, p := range s.Points {
+		points[i] = Point{X: p.X + dx, Y: p.Y + dy}
+	}
+	return NewShape(points)
+}
+
+// Contains reports whether a point is inside the polygon (even-odd rule, works for concave shapes)
+func (s Shape) Contains(x, y float64) bool {
+	if x < s.MinX || x > s.MaxX || y < s.MinY || y > s.MaxY {
+		return false
+	}
+
+	inside := false
+	n := len(s.Points)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := s.Points[i], s.Points[j]
+		if (a.Y > y) != (b.Y > y) && x < (b.X-a.X)*(y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// OverlapsRect checks the polygon against an axis-aligned rectangle.
+// Touching edges don't count, same as image.Rectangle.Overlaps.
+func (s Shape) OverlapsRect(x, y, w, h float64) bool {
+	if len(s.Points) < 3 {
+		return false
+	}
+	if x >= s.MaxX || x+w <= s.MinX || y >= s.MaxY || y+h <= s.MinY {
+		return false
+	}
+
+	// A polygon corner inside the rect
+	for _, p := range s.Points {
+		if p.X > x && p.X < x+w && p.Y > y && p.Y < y+h {
+			return true
+		}
+	}
+
+	// The rect center inside the polygon (covers rect fully inside a big polygon)
+	if s.Contains(x+w/2, y+h/2) {
+		return true
+	}
+
+	// Any polygon edge crossing a rect edge
+	corners := [4]Point{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}
+	n := len(s.Points)
+	for i := 0; i < n; i++ {
+		a, b := s.Points[i], s.Points[(i+1)%n]
+		for j := 0; j < 4; j++ {
+			if segmentsCross(a, b, corners[j], corners[(j+1)%4]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// segmentsCross is a strict segment intersection test (shared endpoints don't count)
+func segmentsCross(a, b, c, d Point) bool {
+	cross := func(o, p, q Point) float64 {
+		return (p.X-o.X)*(q.Y-o.Y) - (p.Y-o.Y)*(q.X-o.X)
+	}
+	d1 := cross(c, d, a)
+	d2 := cross(c, d, b)
+	d3 := cross(a, b, c)
+	d4 := cross(a, b, d)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) &&
+		((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
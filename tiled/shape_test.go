@@ -0,0 +1,80 @@
+package tiled
+
+import (
+	"math"
+	"testing"
+)
+
+func TestObjectShape(t *testing.T) {
+	tests := []struct {
+		name                   string
+		obj                    Object
+		ok                     bool
+		minX, minY, maxX, maxY float64
+	}{
+		{"rectangle", Object{X: 10, Y: 20, Width: 30, Height: 40}, true, 10, 20, 40, 60},
+		{"ellipse", Object{X: 0, Y: 0, Width: 20, Height: 10, Ellipse: true}, true, 0, 0, 20, 10},
+		{"polygon", Object{X: 5, Y: 5, Polygon: []Point{{0, 0}, {10, 0}, {0, -10}}}, true, 5, -5, 15, 5},
+		{"rotated", Object{X: 0, Y: 0, Width: 10, Height: 2, Rotation: 90}, true, -2, 0, 0, 10},
+		{"point", Object{X: 3, Y: 3, Point: true}, false, 0, 0, 0, 0},
+		{"zero size", Object{X: 3, Y: 3}, false, 0, 0, 0, 0},
+		{"two point polygon", Object{Polygon: []Point{{0, 0}, {5, 5}}}, false, 0, 0, 0, 0},
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	for _, tt := range tests {
+		s, ok := ObjectShape(&tt.obj)
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+			continue
+		}
+		if ok && !(near(s.MinX, tt.minX) && near(s.MinY, tt.minY) && near(s.MaxX, tt.maxX) && near(s.MaxY, tt.maxY)) {
+			t.Errorf("%s: bounds (%v,%v)-(%v,%v), want (%v,%v)-(%v,%v)",
+				tt.name, s.MinX, s.MinY, s.MaxX, s.MaxY, tt.minX, tt.minY, tt.maxX, tt.maxY)
+		}
+	}
+}
+
+func TestShapeContainsAndOverlaps(t *testing.T) {
+	// An L: the top-right of its bounding box is empty
+	l := NewShape([]Point{{0, 0}, {10, 0}, {10, 20}, {20, 20}, {20, 30}, {0, 30}})
+
+	points := []struct {
+		x, y float64
+		want bool
+	}{
+		{5, 5, true},
+		{15, 25, true},
+		{15, 5, false}, // in the notch
+		{25, 25, false},
+		{-1, 5, false},
+	}
+	for _, p := range points {
+		if got := l.Contains(p.x, p.y); got != p.want {
+			t.Errorf("Contains(%v, %v) = %v, want %v", p.x, p.y, got, p.want)
+		}
+	}
+
+	rects := []struct {
+		name       string
+		x, y, w, h float64
+		want       bool
+	}{
+		{"corner inside", 8, 8, 4, 4, true},
+		{"inside the shape", 2, 2, 2, 2, true},
+		{"around the shape", -5, -5, 40, 40, true},
+		{"edge crossing", 5, -2, 2, 40, true},
+		{"in the notch", 12, 2, 6, 6, false},
+		{"touching", 20, 20, 5, 5, false},
+		{"far away", 100, 100, 5, 5, false},
+	}
+	for _, r := range rects {
+		if got := l.OverlapsRect(r.x, r.y, r.w, r.h); got != r.want {
+			t.Errorf("OverlapsRect %s = %v, want %v", r.name, got, r.want)
+		}
+	}
+
+	moved := l.Translate(100, 50)
+	if moved.MinX != 100 || moved.MinY != 50 || !moved.Contains(105, 55) || l.MinX != 0 {
+		t.Errorf("Translate moved the copy to %v,%v and the original to %v", moved.MinX, moved.MinY, l.MinX)
+	}
+}
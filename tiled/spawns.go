@@ -1,5 +1,10 @@
 package tiled
 
+import (
+	"errors"
+	"fmt"
+)
+
 // ExtractTaxiSpawns scans the map and returns all taxi spawns
 func ExtractTaxiSpawns(m *Map) []Spawn {
 	var spawns []Spawn
@@ -59,6 +64,7 @@ func ExtractSpawns(m *Map, layerName string) []Spawn {
 						Y:         obj.Y,
 						Type:      obj.Name,                               // name in Tiled becomes the type
 						Direction: obj.GetStringProperty("direction", ""), // optional
+						Location:  obj.GetStringProperty("location", ""),  // optional, names a FINISH
 					})
 				}
 			}
@@ -105,3 +111,53 @@ func ExtractManifestCheckpoints(m *Map) []Spawn {
 	walkLayers(m.Layers)
 	return spawns
 }
+
+// Fixed race positions on the Spawns layer, by object name
+const (
+	SpawnLayer       = "Spawns"
+	SpawnPlayerStart = "PLAYER_START"
+	SpawnRivalStart  = "RIVAL_START"
+	SpawnFinish      = "FINISH" // optional, without one the manifest picks a checkpoint
+)
+
+// ErrSpawnNotFound is wrapped by FindSpawn when the map doesn't have the object
+var ErrSpawnNotFound = errors.New("spawn not found")
+
+// RaceSpawns are where a race starts (and maybe ends), in map pixels
+type RaceSpawns struct {
+	PlayerStart Spawn
+	RivalStart  Spawn
+	Finish      *Spawn // nil when the map has no FINISH object
+}
+
+// FindSpawn returns the first object with this name on an object layer
+func FindSpawn(m *Map, layerName, name string) (Spawn, error) {
+	for _, s := range ExtractSpawns(m, layerName) {
+		if s.Type == name {
+			return s, nil
+		}
+	}
+	return Spawn{}, fmt.Errorf("tiled: no %s object on the %q layer, add a point named %s there in Tiled: %w",
+		name, layerName, name, ErrSpawnNotFound)
+}
+
+// ExtractRaceSpawns reads PLAYER_START, RIVAL_START and the optional FINISH from the
+// Spawns layer. It names every missing object at once, so a new map gets fixed in one go.
+func ExtractRaceSpawns(m *Map) (RaceSpawns, error) {
+	var rs RaceSpawns
+	var errs []error
+
+	var err error
+	if rs.PlayerStart, err = FindSpawn(m, SpawnLayer, SpawnPlayerStart); err != nil {
+		errs = append(errs, err)
+	}
+	if rs.RivalStart, err = FindSpawn(m, SpawnLayer, SpawnRivalStart); err != nil {
+		errs = append(errs, err)
+	}
+
+	if finish, err := FindSpawn(m, SpawnLayer, SpawnFinish); err == nil {
+		rs.Finish = &finish
+	}
+
+	return rs, errors.Join(errs...)
+}
@@ -0,0 +1,74 @@
+package tiled
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// spawnMap has one object layer with a point per name, 16px apart
+func spawnMap(layer string, names ...string) *Map {
+	l := Layer{Name: layer, Type: "objectgroup", Visible: true}
+	for i, n := range names {
+		l.Objects = append(l.Objects, Object{Name: n, X: float64(16 * (i + 1)), Y: 8, Point: true})
+	}
+	return &Map{Layers: []Layer{{Type: "group", Visible: true, Layers: []Layer{l}}}}
+}
+
+func TestExtractRaceSpawns(t *testing.T) {
+	m := spawnMap(SpawnLayer, "CHECKPOINT", SpawnPlayerStart, SpawnRivalStart, SpawnFinish)
+	m.Layers[0].Layers[0].Objects[3].Properties = Properties{{Name: "location", Type: "string", Value: "Pier 17"}}
+
+	rs, err := ExtractRaceSpawns(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.PlayerStart.X != 32 || rs.RivalStart.X != 48 {
+		t.Errorf("starts at %v and %v, want 32 and 48", rs.PlayerStart.X, rs.RivalStart.X)
+	}
+	if rs.Finish == nil || rs.Finish.X != 64 || rs.Finish.Location != "Pier 17" {
+		t.Errorf("Finish = %+v, want the one at 64 named Pier 17", rs.Finish)
+	}
+
+	// FINISH is optional
+	rs, err = ExtractRaceSpawns(spawnMap(SpawnLayer, SpawnPlayerStart, SpawnRivalStart))
+	if err != nil || rs.Finish != nil {
+		t.Errorf("no FINISH: got %+v, %v", rs.Finish, err)
+	}
+}
+
+func TestExtractRaceSpawnsMissing(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       *Map
+		missing []string
+	}{
+		{"no player", spawnMap(SpawnLayer, SpawnRivalStart), []string{SpawnPlayerStart}},
+		{"no rival", spawnMap(SpawnLayer, SpawnPlayerStart, SpawnFinish), []string{SpawnRivalStart}},
+		{"empty layer", spawnMap(SpawnLayer), []string{SpawnPlayerStart, SpawnRivalStart}},
+		{"wrong layer", spawnMap("Taxis", SpawnPlayerStart, SpawnRivalStart), []string{SpawnPlayerStart, SpawnRivalStart}},
+		{"no layers", &Map{}, []string{SpawnPlayerStart, SpawnRivalStart}},
+	}
+	for _, tt := range tests {
+		_, err := ExtractRaceSpawns(tt.m)
+		if !errors.Is(err, ErrSpawnNotFound) {
+			t.Errorf("%s: err = %v, want ErrSpawnNotFound", tt.name, err)
+			continue
+		}
+		// Every missing object is named, and only those
+		for _, n := range []string{SpawnPlayerStart, SpawnRivalStart} {
+			want := strings.Contains(strings.Join(tt.missing, " "), n)
+			if got := strings.Contains(err.Error(), "no "+n+" object"); got != want {
+				t.Errorf("%s: error mentions %s = %v, want %v:\n%v", tt.name, n, got, want, err)
+			}
+		}
+	}
+}
+
+func TestExtractRaceSpawnsHiddenLayer(t *testing.T) {
+	m := spawnMap(SpawnLayer, SpawnPlayerStart, SpawnRivalStart)
+	m.Layers[0].Visible = false
+	if _, err := ExtractRaceSpawns(m); !errors.Is(err, ErrSpawnNotFound) {
+		t.Errorf("spawns on a hidden layer were used, err = %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package tiled
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Template is an object template (.tx or .tj) that objects in a map can point at.
+// Tiled only writes the fields an instance changed, everything else comes from here.
+type Template struct {
+	Object Object `json:"object"`
+}
+
+// LoadTemplateFS reads a Tiled template, either XML (.tx) or JSON (.tj)
+func LoadTemplateFS(fsys fs.FS, templatePath string) (*Template, error) {
+	b, err := fs.ReadFile(fsys, templatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var t Template
+	if strings.EqualFold(path.Ext(templatePath), ".tj") {
+		if err := json.Unmarshal(b, &t); err != nil {
+			return nil, fmt.Errorf("tiled: template %s: %w", templatePath, err)
+		}
+		return &t, nil
+	}
+
+	var raw xmlTemplate
+	if err := xml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("tiled: template %s: %w", templatePath, err)
+	}
+	obj, err := raw.Object.toObject()
+	if err != nil {
+		return nil, fmt.Errorf("tiled: template %s: %w", templatePath, err)
+	}
+	t.Object = obj
+	return &t, nil
+}
+
+// resolveTemplates fills in every object that only carries a "template" reference.
+// Template paths are relative to the map file, so dir is the map's directory.
+func resolveTemplates(fsys fs.FS, dir string, m *Map) error {
+	cache := map[string]*Template{}
+
+	var walk func(layers []Layer) error
+	walk = func(layers []Layer) error {
+		for li := range layers {
+			layer := &layers[li]
+			if layer.Type == "group" {
+				if err := walk(layer.Layers); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for oi := range layer.Objects {
+				obj := &layer.Objects[oi]
+				if obj.Template == "" {
+					continue
+				}
+
+				p := path.Join(dir, obj.Template)
+				t, ok := cache[p]
+				if !ok {
+					var err error
+					t, err = LoadTemplateFS(fsys, p)
+					if err != nil {
+						return err
+					}
+					cache[p] = t
+				}
+				obj.applyTemplate(&t.Object)
+			}
+		}
+		return nil
+	}
+
+	return walk(m.Layers)
+}
+
+// applyTemplate merges template defaults into the object.
+// Anything set on the instance wins, properties are matched by name.
+func (o *Object) applyTemplate(t *Object) {
+	if o.Name == "" {
+		o.Name = t.Name
+	}
+	if o.Type == "" {
+		o.Type = t.Type
+	}
+	if t.Point {
+		o.Point = true
+	}
+	if t.Ellipse {
+		o.Ellipse = true
+	}
+	if o.Width == 0 && o.Height == 0 {
+		o.Width, o.Height = t.Width, t.Height
+	}
+	if o.Polygon == nil {
+		o.Polygon = t.Polygon
+	}
+
+	merged := make([]ObjectProperty, 0, len(t.Properties)+len(o.Properties))
+	for _, tp := range t.Properties {
+		overridden := false
+		for _, p := range o.Properties {
+			if p.Name == tp.Name {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, tp)
+		}
+	}
+	o.Properties = append(merged, o.Properties...)
+}
+
+// --- XML shapes (.tx) ---
+
+type xmlTemplate struct {
+	Object xmlObject `xml:"object"`
+}
@@ -0,0 +1,83 @@
+package tiled
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+const testTaxiTX = `<?xml version="1.0" encoding="UTF-8"?>
+<template>
+ <object name="taxi" type="car">
+  <properties>
+   <property name="direction" value="LEFT"/>
+   <property name="speed" type="float" value="2.5"/>
+  </properties>
+  <point/>
+ </object>
+</template>`
+
+const testStopTJ = `{"object": {"name": "CHECKPOINT", "type": "stop",
+  "properties": [{"name": "location", "type": "string", "value": "Somewhere"}]}}`
+
+// Two placed taxis (one turned around) and a stop, the second layer inside a group
+const testTemplateMap = `{"width": 4, "height": 4, "tilewidth": 16, "tileheight": 16, "layers": [
+  {"name": "Taxis", "type": "objectgroup", "visible": true, "objects": [
+    {"id": 1, "template": "../templates/taxi.tx", "x": 16, "y": 32},
+    {"id": 2, "template": "../templates/taxi.tx", "x": 48, "y": 32,
+     "properties": [{"name": "direction", "type": "string", "value": "UP"}]}
+  ]},
+  {"name": "Stops", "type": "group", "visible": true, "layers": [
+    {"name": "Spawns", "type": "objectgroup", "visible": true, "objects": [
+      {"id": 3, "template": "../templates/stop.tj", "name": "PIZZA", "x": 8, "y": 8,
+       "properties": [{"name": "location", "type": "string", "value": "Joe's"}]}
+    ]}
+  ]}
+]}`
+
+func TestLoadMapTemplates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"maps/city.tmj":     {Data: []byte(testTemplateMap)},
+		"templates/taxi.tx": {Data: []byte(testTaxiTX)},
+		"templates/stop.tj": {Data: []byte(testStopTJ)},
+		"maps/broken.tmj":   {Data: []byte(`{"layers": [{"type": "objectgroup", "objects": [{"template": "nope.tx"}]}]}`)},
+	}
+	m, err := LoadMapFS(fsys, "maps/city.tmj")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taxis := m.Layers[0].Objects
+	for i, want := range []string{"LEFT", "UP"} {
+		o := taxis[i]
+		if o.Name != "taxi" || o.Type != "car" || !o.Point {
+			t.Errorf("taxi %d: name %q type %q point %v, want the template's", i, o.Name, o.Type, o.Point)
+		}
+		if got := o.GetStringProperty("direction", ""); got != want {
+			t.Errorf("taxi %d: direction %q, want %q", i, got, want)
+		}
+		speed := 0.0
+		for _, p := range o.Properties {
+			if p.Name == "speed" {
+				speed, _ = p.Value.(float64)
+			}
+		}
+		if speed != 2.5 {
+			t.Errorf("taxi %d: speed %v, want the template's 2.5", i, speed)
+		}
+		if len(o.Properties) != 2 {
+			t.Errorf("taxi %d: %d properties, an override shouldn't double up", i, len(o.Properties))
+		}
+	}
+	if taxis[1].X != 48 || taxis[1].Y != 32 {
+		t.Errorf("taxi 2 at %v,%v, the instance's position has to win", taxis[1].X, taxis[1].Y)
+	}
+
+	stop := m.Layers[1].Layers[0].Objects[0]
+	if stop.Name != "PIZZA" || stop.Type != "stop" || stop.GetStringProperty("location", "") != "Joe's" {
+		t.Errorf("stop in a group: %q %q at %q", stop.Name, stop.Type, stop.GetStringProperty("location", ""))
+	}
+
+	if _, err := LoadMapFS(fsys, "maps/broken.tmj"); err == nil {
+		t.Error("a missing template loaded fine")
+	}
+}
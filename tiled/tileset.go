@@ -0,0 +1,102 @@
+package tiled
+
+import "image"
+
+// GIDs in layer data carry flip flags in the top bits, the rest is the tile id.
+// H+V+D together encode the four rotations, flipHex is the extra 120° turn on hexagonal maps.
+const (
+	flipH    = 0x80000000
+	flipV    = 0x40000000
+	flipD    = 0x20000000
+	flipHex  = 0x10000000
+	flipMask = flipH | flipV | flipD | flipHex
+)
+
+// TilesetForGID finds which tileset owns a GID (flip bits are ignored).
+// Returns the index into m.Tilesets and the tile's local id in that tileset,
+// or -1 if no tileset covers it.
+func (m *Map) TilesetForGID(gid uint32) (int, int) {
+	gid &^= flipMask
+	if gid == 0 {
+		return -1, 0
+	}
+
+	// Tilesets are stored in firstgid order, the owner is the last one that starts at or before gid
+	best := -1
+	for i, ts := range m.Tilesets {
+		if uint32(ts.FirstGID) <= gid && (best < 0 || ts.FirstGID > m.Tilesets[best].FirstGID) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return -1, 0
+	}
+
+	local := int(gid) - m.Tilesets[best].FirstGID
+	if tc := m.Tilesets[best].TileCount; tc > 0 && local >= tc {
+		return -1, 0
+	}
+	return best, local
+}
+
+// TileColumns is how many tiles fit across the tileset image.
+// imageWidth is only used when the tileset doesn't say (older exports).
+func (ts *Tileset) TileColumns(imageWidth int) int {
+	if ts.Columns > 0 {
+		return ts.Columns
+	}
+	if ts.ImageWidth > 0 {
+		imageWidth = ts.ImageWidth
+	}
+	if ts.TileWidth <= 0 {
+		return 0
+	}
+	return (imageWidth - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+}
+
+// TileRect is the source rectangle of a local tile id inside the tileset image,
+// taking margin and spacing into account.
+func (ts *Tileset) TileRect(localID, imageWidth int) image.Rectangle {
+	cols := ts.TileColumns(imageWidth)
+	if cols <= 0 {
+		return image.Rectangle{}
+	}
+
+	x := ts.Margin + (localID%cols)*(ts.TileWidth+ts.Spacing)
+	y := ts.Margin + (localID/cols)*(ts.TileHeight+ts.Spacing)
+	return image.Rect(x, y, x+ts.TileWidth, y+ts.TileHeight)
+}
+
+// Tile returns the extra data for a local tile id, or nil if Tiled stored none
+func (ts *Tileset) Tile(localID int) *TileDef {
+	for i := range ts.Tiles {
+		if ts.Tiles[i].ID == localID {
+			return &ts.Tiles[i]
+		}
+	}
+	return nil
+}
+
+// FrameAt picks the animation frame showing at time t (ms since the animation started).
+// Returns the local tile id to draw.
+func (td *TileDef) FrameAt(t int64) int {
+	total := int64(0)
+	for _, f := range td.Animation {
+		total += int64(f.Duration)
+	}
+	if total <= 0 {
+		if len(td.Animation) > 0 {
+			return td.Animation[0].TileID
+		}
+		return td.ID
+	}
+
+	t %= total
+	for _, f := range td.Animation {
+		if t < int64(f.Duration) {
+			return f.TileID
+		}
+		t -= int64(f.Duration)
+	}
+	return td.Animation[len(td.Animation)-1].TileID
+}
@@ -0,0 +1,96 @@
+package tiled
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+func TestTileRect(t *testing.T) {
+	// 1px margin, 2px spacing: 16px tiles start at 1, 19, 37...
+	ts := &Tileset{TileWidth: 16, TileHeight: 16, Margin: 1, Spacing: 2}
+	if cols := ts.TileColumns(56); cols != 3 {
+		t.Errorf("TileColumns(56) = %d, want 3", cols)
+	}
+	tests := []struct {
+		local int
+		want  image.Rectangle
+	}{
+		{0, image.Rect(1, 1, 17, 17)},
+		{2, image.Rect(37, 1, 53, 17)},
+		{4, image.Rect(19, 19, 35, 35)},
+	}
+	for _, tt := range tests {
+		if got := ts.TileRect(tt.local, 56); got != tt.want {
+			t.Errorf("TileRect(%d) = %v, want %v", tt.local, got, tt.want)
+		}
+	}
+
+	// What the tileset says wins over the image it's given
+	ts = &Tileset{TileWidth: 16, TileHeight: 16, Columns: 4, ImageWidth: 80}
+	if cols := ts.TileColumns(32); cols != 4 {
+		t.Errorf("TileColumns with columns set = %d, want 4", cols)
+	}
+	ts.Columns = 0
+	if cols := ts.TileColumns(32); cols != 5 {
+		t.Errorf("TileColumns with imagewidth set = %d, want 5", cols)
+	}
+	if got := (&Tileset{}).TileRect(3, 64); !got.Empty() {
+		t.Errorf("TileRect without a tile size = %v, want empty", got)
+	}
+}
+
+func TestFrameAt(t *testing.T) {
+	td := &TileDef{ID: 4, Animation: []Frame{{TileID: 4, Duration: 100}, {TileID: 5, Duration: 50}, {TileID: 6, Duration: 100}}}
+	tests := []struct {
+		ms   int64
+		want int
+	}{
+		{0, 4},
+		{99, 4},
+		{100, 5},
+		{149, 5},
+		{150, 6},
+		{249, 6},
+		{250, 4}, // and around again
+		{250*4 + 120, 5},
+	}
+	for _, tt := range tests {
+		if got := td.FrameAt(tt.ms); got != tt.want {
+			t.Errorf("FrameAt(%d) = %d, want %d", tt.ms, got, tt.want)
+		}
+	}
+
+	if got := (&TileDef{ID: 7}).FrameAt(500); got != 7 {
+		t.Errorf("no animation: FrameAt = %d, want the tile itself", got)
+	}
+	if got := (&TileDef{ID: 7, Animation: []Frame{{TileID: 8}}}).FrameAt(500); got != 8 {
+		t.Errorf("zero length animation: FrameAt = %d, want its first frame", got)
+	}
+}
+
+func TestAnimatedGID(t *testing.T) {
+	m := &Map{Tilesets: []Tileset{
+		{FirstGID: 1, TileCount: 4},
+		{FirstGID: 5, TileCount: 4, Tiles: []TileDef{
+			{ID: 1, Animation: []Frame{{TileID: 1, Duration: 100}, {TileID: 3, Duration: 100}}},
+		}},
+	}}
+	r := &Renderer{Map: m}
+
+	raw := uint32(flipH | 6)
+	td := r.animation(raw)
+	if td == nil {
+		t.Fatal("flipped animated tile not found")
+	}
+	if r.animation(5) != nil || r.animation(2) != nil {
+		t.Error("plain tiles came back animated")
+	}
+	if got := r.animatedGID(raw, td); got != flipH|6 {
+		t.Errorf("at 0ms: %#x, want the first frame", got)
+	}
+	r.Clock = 150 * time.Millisecond
+	if got := r.animatedGID(raw, td); got != flipH|8 {
+		t.Errorf("at 150ms: %#x, want the second frame with the flip kept", got)
+	}
+}
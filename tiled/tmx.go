@@ -0,0 +1,583 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TMX is Tiled's native XML format. Everything here decodes into the same
+// Map/Layer/Tileset/Object structs the JSON (.tmj) path fills in.
+
+// xmlMap and xmlLayer decode themselves (see UnmarshalXML) so draw order survives
+type xmlMap struct {
+	Orientation string
+	Width       int
+	Height      int
+	TileWidth   int
+	TileHeight  int
+	Infinite    bool
+	Tilesets    []xmlTileset
+	Layers      xmlLayerList
+	Properties  []xmlProperty
+}
+
+type xmlTileset struct {
+	FirstGID   int       `xml:"firstgid,attr"`
+	Source     string    `xml:"source,attr"`
+	Name       string    `xml:"name,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	TileCount  int       `xml:"tilecount,attr"`
+	Columns    int       `xml:"columns,attr"`
+	Margin     int       `xml:"margin,attr"`
+	Spacing    int       `xml:"spacing,attr"`
+	Image      xmlImage  `xml:"image"`
+	Tiles      []xmlTile `xml:"tile"`
+
+	Properties []xmlProperty `xml:"properties>property"`
+}
+
+type xmlTile struct {
+	ID         int           `xml:"id,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Animation  []struct {
+		TileID   int `xml:"tileid,attr"`
+		Duration int `xml:"duration,attr"`
+	} `xml:"animation>frame"`
+	ObjectGroup *xmlLayer `xml:"objectgroup"`
+}
+
+type xmlImage struct {
+	Source string `xml:"source,attr"`
+	Trans  string `xml:"trans,attr"` // "ff00ff", no #
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type xmlLayer struct {
+	ID      int
+	Name    string
+	Visible *int // missing means visible
+	Data    xmlData
+	Objects []xmlObject
+	Layers  xmlLayerList // only for groups
+
+	Properties []xmlProperty
+}
+
+type xmlData struct {
+	Encoding    string       `xml:"encoding,attr"`
+	Compression string       `xml:"compression,attr"`
+	Tiles       []xmlTileRef `xml:"tile"`
+	Text        string       `xml:",chardata"`
+	Chunks      []xmlChunk   `xml:"chunk"` // infinite maps only, same encoding as the parent <data>
+}
+
+type xmlTileRef struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+type xmlChunk struct {
+	X      int          `xml:"x,attr"`
+	Y      int          `xml:"y,attr"`
+	Width  int          `xml:"width,attr"`
+	Height int          `xml:"height,attr"`
+	Tiles  []xmlTileRef `xml:"tile"`
+	Text   string       `xml:",chardata"`
+}
+
+type xmlObject struct {
+	ID       int       `xml:"id,attr"`
+	Name     string    `xml:"name,attr"`
+	Type     string    `xml:"type,attr"`
+	Class    string    `xml:"class,attr"` // Tiled 1.9 wrote "class" instead of "type"
+	X        float64   `xml:"x,attr"`
+	Y        float64   `xml:"y,attr"`
+	Width    float64   `xml:"width,attr"`
+	Height   float64   `xml:"height,attr"`
+	Rotation float64   `xml:"rotation,attr"`
+	Template string    `xml:"template,attr"`
+	Point    *struct{} `xml:"point"`
+	Ellipse  *struct{} `xml:"ellipse"`
+	Polygon  *struct {
+		Points string `xml:"points,attr"` // "x1,y1 x2,y2 ..."
+	} `xml:"polygon"`
+	Properties []xmlProperty `xml:"properties>property"`
+}
+
+type xmlProperty struct {
+	Name         string `xml:"name,attr"`
+	Type         string `xml:"type,attr"`
+	PropertyType string `xml:"propertytype,attr"`
+	Value        string `xml:"value,attr"`
+	Text         string `xml:",chardata"` // multi-line strings live in the element body
+
+	Members []xmlProperty `xml:"properties>property"` // only for class properties
+}
+
+// xmlProperties is a bare <properties> element, for the elements that decode themselves
+type xmlProperties struct {
+	List []xmlProperty `xml:"property"`
+}
+
+// xmlLayerList keeps layers, object groups and groups in document order.
+// encoding/xml would otherwise split them into one slice per element name.
+type xmlLayerList []xmlLayerEntry
+
+type xmlLayerEntry struct {
+	Kind  string // "tilelayer" | "objectgroup" | "group" | "imagelayer"
+	Layer xmlLayer
+}
+
+func (m *xmlMap) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		var err error
+		switch a.Name.Local {
+		case "orientation":
+			m.Orientation = a.Value
+		case "width":
+			m.Width, err = strconv.Atoi(a.Value)
+		case "height":
+			m.Height, err = strconv.Atoi(a.Value)
+		case "tilewidth":
+			m.TileWidth, err = strconv.Atoi(a.Value)
+		case "tileheight":
+			m.TileHeight, err = strconv.Atoi(a.Value)
+		case "infinite":
+			m.Infinite = a.Value == "1"
+		}
+		if err != nil {
+			return fmt.Errorf("map %s: %w", a.Name.Local, err)
+		}
+	}
+
+	return decodeChildren(d, func(el xml.StartElement) (bool, error) {
+		switch el.Name.Local {
+		case "tileset":
+			var ts xmlTileset
+			if err := d.DecodeElement(&ts, &el); err != nil {
+				return true, err
+			}
+			m.Tilesets = append(m.Tilesets, ts)
+			return true, nil
+		case "properties":
+			var props xmlProperties
+			if err := d.DecodeElement(&props, &el); err != nil {
+				return true, err
+			}
+			m.Properties = props.List
+			return true, nil
+		}
+		return false, nil
+	}, &m.Layers)
+}
+
+func (l *xmlLayer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "id":
+			l.ID, _ = strconv.Atoi(a.Value)
+		case "name":
+			l.Name = a.Value
+		case "visible":
+			v, _ := strconv.Atoi(a.Value)
+			l.Visible = &v
+		}
+	}
+
+	return decodeChildren(d, func(el xml.StartElement) (bool, error) {
+		switch el.Name.Local {
+		case "data":
+			return true, d.DecodeElement(&l.Data, &el)
+		case "object":
+			var obj xmlObject
+			if err := d.DecodeElement(&obj, &el); err != nil {
+				return true, err
+			}
+			l.Objects = append(l.Objects, obj)
+			return true, nil
+		case "properties":
+			var props xmlProperties
+			if err := d.DecodeElement(&props, &el); err != nil {
+				return true, err
+			}
+			l.Properties = props.List
+			return true, nil
+		}
+		return false, nil
+	}, &l.Layers)
+}
+
+// decodeChildren walks the children of the current element. Layer-like children
+// go into layers (in order), handle gets first look at everything else.
+func decodeChildren(d *xml.Decoder, handle func(xml.StartElement) (bool, error), layers *xmlLayerList) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch el := tok.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			kind := ""
+			switch el.Name.Local {
+			case "layer":
+				kind = "tilelayer"
+			case "objectgroup", "group", "imagelayer":
+				kind = el.Name.Local
+			}
+
+			if kind != "" {
+				var layer xmlLayer
+				if err := d.DecodeElement(&layer, &el); err != nil {
+					return err
+				}
+				*layers = append(*layers, xmlLayerEntry{Kind: kind, Layer: layer})
+				continue
+			}
+
+			handled, err := handle(el)
+			if err != nil {
+				return err
+			}
+			if !handled {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// decodeTMX parses a .tmx file. External tilesets and templates are left as
+// references, LoadMapFS resolves them against the file system afterwards.
+func decodeTMX(b []byte) (*Map, error) {
+	var raw xmlMap
+	if err := xml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Orientation: raw.Orientation,
+		Width:       raw.Width,
+		Height:      raw.Height,
+		TileWidth:   raw.TileWidth,
+		TileHeight:  raw.TileHeight,
+		Infinite:    raw.Infinite,
+	}
+
+	for _, xt := range raw.Tilesets {
+		ts, err := xt.toTileset()
+		if err != nil {
+			return nil, fmt.Errorf("tileset %q: %w", xt.Name, err)
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	layers, err := raw.Layers.toLayers()
+	if err != nil {
+		return nil, err
+	}
+	m.Layers = layers
+
+	if m.Properties, err = convertXMLProperties(raw.Properties); err != nil {
+		return nil, fmt.Errorf("map: %w", err)
+	}
+	return m, nil
+}
+
+// decodeTSX parses an external .tsx tileset
+func decodeTSX(b []byte) (*Tileset, error) {
+	var raw xmlTileset
+	if err := xml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	ts, err := raw.toTileset()
+	if err != nil {
+		return nil, err
+	}
+	return &ts, nil
+}
+
+func (x xmlTileset) toTileset() (Tileset, error) {
+	ts := Tileset{
+		FirstGID:    x.FirstGID,
+		Source:      x.Source,
+		Name:        x.Name,
+		Image:       x.Image.Source,
+		ImageWidth:  x.Image.Width,
+		ImageHeight: x.Image.Height,
+		TileWidth:   x.TileWidth,
+		TileHeight:  x.TileHeight,
+		TileCount:   x.TileCount,
+		Columns:     x.Columns,
+		Margin:      x.Margin,
+		Spacing:     x.Spacing,
+	}
+	if x.Image.Trans != "" {
+		ts.TransparentColor = "#" + x.Image.Trans
+	}
+
+	props, err := convertXMLProperties(x.Properties)
+	if err != nil {
+		return ts, err
+	}
+	ts.Properties = props
+
+	for _, xt := range x.Tiles {
+		td := TileDef{ID: xt.ID, Type: xt.Type}
+		if td.Type == "" {
+			td.Type = xt.Class
+		}
+
+		props, err := convertXMLProperties(xt.Properties)
+		if err != nil {
+			return ts, fmt.Errorf("tile %d: %w", xt.ID, err)
+		}
+		td.Properties = props
+
+		for _, f := range xt.Animation {
+			td.Animation = append(td.Animation, Frame{TileID: f.TileID, Duration: f.Duration})
+		}
+
+		if xt.ObjectGroup != nil {
+			group := Layer{ID: xt.ObjectGroup.ID, Name: xt.ObjectGroup.Name, Type: "objectgroup", Visible: true}
+			for _, xo := range xt.ObjectGroup.Objects {
+				obj, err := xo.toObject()
+				if err != nil {
+					return ts, fmt.Errorf("tile %d: %w", xt.ID, err)
+				}
+				group.Objects = append(group.Objects, obj)
+			}
+			td.ObjectGroup = &group
+		}
+		ts.Tiles = append(ts.Tiles, td)
+	}
+	return ts, nil
+}
+
+func (list xmlLayerList) toLayers() ([]Layer, error) {
+	var out []Layer
+	for _, item := range list {
+		x := item.Layer
+		layer := Layer{
+			ID:      x.ID,
+			Name:    x.Name,
+			Type:    item.Kind,
+			Visible: x.Visible == nil || *x.Visible != 0,
+		}
+
+		props, err := convertXMLProperties(x.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("layer %q: %w", x.Name, err)
+		}
+		layer.Properties = props
+
+		switch item.Kind {
+		case "tilelayer":
+			if len(x.Data.Chunks) > 0 {
+				for _, xc := range x.Data.Chunks {
+					data, err := decodeLayerData(xmlData{
+						Encoding:    x.Data.Encoding,
+						Compression: x.Data.Compression,
+						Tiles:       xc.Tiles,
+						Text:        xc.Text,
+					})
+					if err != nil {
+						return nil, fmt.Errorf("layer %q chunk %d,%d: %w", x.Name, xc.X, xc.Y, err)
+					}
+					layer.Chunks = append(layer.Chunks, Chunk{X: xc.X, Y: xc.Y, Width: xc.Width, Height: xc.Height, Data: data})
+				}
+				break
+			}
+
+			data, err := decodeLayerData(x.Data)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", x.Name, err)
+			}
+			layer.Data = data
+		case "objectgroup":
+			for _, xo := range x.Objects {
+				obj, err := xo.toObject()
+				if err != nil {
+					return nil, fmt.Errorf("layer %q: %w", x.Name, err)
+				}
+				layer.Objects = append(layer.Objects, obj)
+			}
+		case "group":
+			sub, err := x.Layers.toLayers()
+			if err != nil {
+				return nil, err
+			}
+			layer.Layers = sub
+		}
+
+		out = append(out, layer)
+	}
+	return out, nil
+}
+
+// decodeLayerData handles every way Tiled stores tile GIDs in XML:
+// <tile> elements, csv, and base64 (raw, zlib or gzip compressed).
+func decodeLayerData(d xmlData) ([]uint32, error) {
+	switch d.Encoding {
+	case "":
+		gids := make([]uint32, len(d.Tiles))
+		for i, t := range d.Tiles {
+			gids[i] = t.GID
+		}
+		return gids, nil
+
+	case "csv":
+		fields := strings.Split(strings.TrimSpace(d.Text), ",")
+		gids := make([]uint32, 0, len(fields))
+		for _, f := range fields {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("csv data: %w", err)
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+
+	case "base64":
+		b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(d.Text))
+		if err != nil {
+			return nil, fmt.Errorf("base64 data: %w", err)
+		}
+
+		var r io.Reader = bytes.NewReader(b)
+		switch d.Compression {
+		case "":
+		case "zlib":
+			if r, err = zlib.NewReader(r); err != nil {
+				return nil, fmt.Errorf("zlib data: %w", err)
+			}
+		case "gzip":
+			if r, err = gzip.NewReader(r); err != nil {
+				return nil, fmt.Errorf("gzip data: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported compression %q", d.Compression)
+		}
+
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s data: %w", d.Compression, err)
+		}
+		if len(raw)%4 != 0 {
+			return nil, fmt.Errorf("base64 data: %d bytes is not a multiple of 4", len(raw))
+		}
+
+		gids := make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return gids, nil
+	}
+
+	return nil, fmt.Errorf("unsupported encoding %q", d.Encoding)
+}
+
+func (x xmlObject) toObject() (Object, error) {
+	obj := Object{
+		ID:       x.ID,
+		Name:     x.Name,
+		Type:     x.Type,
+		X:        x.X,
+		Y:        x.Y,
+		Width:    x.Width,
+		Height:   x.Height,
+		Rotation: x.Rotation,
+		Template: x.Template,
+		Point:    x.Point != nil,
+		Ellipse:  x.Ellipse != nil,
+	}
+	if obj.Type == "" {
+		obj.Type = x.Class
+	}
+
+	if x.Polygon != nil {
+		for _, pair := range strings.Fields(x.Polygon.Points) {
+			xs, ys, ok := strings.Cut(pair, ",")
+			if !ok {
+				return obj, fmt.Errorf("object %d: bad polygon point %q", x.ID, pair)
+			}
+			px, err1 := strconv.ParseFloat(xs, 64)
+			py, err2 := strconv.ParseFloat(ys, 64)
+			if err1 != nil || err2 != nil {
+				return obj, fmt.Errorf("object %d: bad polygon point %q", x.ID, pair)
+			}
+			obj.Polygon = append(obj.Polygon, Point{X: px, Y: py})
+		}
+	}
+
+	props, err := convertXMLProperties(x.Properties)
+	if err != nil {
+		return obj, err
+	}
+	obj.Properties = props
+	return obj, nil
+}
+
+// convertXMLProperties turns the string attributes into the same values
+// encoding/json would give us for a .tmj (numbers are float64, bools are bool).
+// Class members become a nested Properties, like the JSON path does.
+func convertXMLProperties(in []xmlProperty) (Properties, error) {
+	var out Properties
+	for _, xp := range in {
+		typ := xp.Type
+		if typ == "" {
+			typ = "string"
+		}
+
+		raw := xp.Value
+		if raw == "" && strings.TrimSpace(xp.Text) != "" {
+			raw = xp.Text
+		}
+
+		var val interface{} = raw
+		switch typ {
+		case "int", "float", "object":
+			if raw == "" {
+				val = 0.0
+				break
+			}
+			f, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("property %q: %w", xp.Name, err)
+			}
+			val = f
+		case "bool":
+			val = raw == "true"
+		case "class":
+			members, err := convertXMLProperties(xp.Members)
+			if err != nil {
+				return nil, fmt.Errorf("property %q: %w", xp.Name, err)
+			}
+			if members == nil {
+				members = Properties{}
+			}
+			val = members
+		}
+
+		out = append(out, ObjectProperty{Name: xp.Name, Type: typ, PropertyType: xp.PropertyType, Value: val})
+	}
+	return out, nil
+}
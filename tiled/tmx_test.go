@@ -0,0 +1,240 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// gidBytes packs GIDs the way Tiled does before base64/compression
+func gidBytes(gids ...uint32) []byte {
+	b := make([]byte, 4*len(gids))
+	for i, g := range gids {
+		binary.LittleEndian.PutUint32(b[i*4:], g)
+	}
+	return b
+}
+
+func compress(t *testing.T, kind string, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch kind {
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	default:
+		return b
+	}
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeLayerData(t *testing.T) {
+	want := []uint32{1, 0, 3, flipH | 2}
+
+	tests := []struct {
+		name string
+		data xmlData
+	}{
+		{"tile elements", xmlData{Tiles: []xmlTileRef{{1}, {0}, {3}, {flipH | 2}}}},
+		{"csv", xmlData{Encoding: "csv", Text: "\n1,0,\n3,2147483650\n"}},
+		{"csv trailing comma", xmlData{Encoding: "csv", Text: "1,0,3,2147483650,"}},
+		{"base64", xmlData{Encoding: "base64", Text: base64.StdEncoding.EncodeToString(gidBytes(want...))}},
+	}
+	for _, c := range []string{"zlib", "gzip"} {
+		tests = append(tests, struct {
+			name string
+			data xmlData
+		}{"base64 " + c, xmlData{Encoding: "base64", Compression: c, Text: "\n  " + base64.StdEncoding.EncodeToString(compress(t, c, gidBytes(want...))) + "\n"}})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeLayerData(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeLayerDataErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data xmlData
+		want string
+	}{
+		{"bad csv", xmlData{Encoding: "csv", Text: "1,x"}, "csv data"},
+		{"bad base64", xmlData{Encoding: "base64", Text: "!!!"}, "base64 data"},
+		{"short base64", xmlData{Encoding: "base64", Text: base64.StdEncoding.EncodeToString([]byte{1, 2, 3})}, "not a multiple of 4"},
+		{"not zlib", xmlData{Encoding: "base64", Compression: "zlib", Text: base64.StdEncoding.EncodeToString(gidBytes(1))}, "zlib data"},
+		{"zstd", xmlData{Encoding: "base64", Compression: "zstd", Text: base64.StdEncoding.EncodeToString(gidBytes(1))}, "unsupported compression"},
+		{"encoding", xmlData{Encoding: "hex"}, "unsupported encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeLayerData(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTilesetForGIDMasksFlipBits(t *testing.T) {
+	m := &Map{Tilesets: []Tileset{
+		{FirstGID: 1, TileCount: 10},
+		{FirstGID: 11, TileCount: 5},
+	}}
+	tests := []struct {
+		gid       uint32
+		ts, local int
+	}{
+		{0, -1, 0},
+		{flipH | flipV | flipD, -1, 0}, // flip bits on an empty cell
+		{1, 0, 0},
+		{flipH | 10, 0, 9},
+		{flipV | flipD | 11, 1, 0},
+		{flipHex | 15, 1, 4},
+		{16, -1, 0}, // past the last tileset's tilecount
+	}
+	for _, tt := range tests {
+		ts, local := m.TilesetForGID(tt.gid)
+		if ts != tt.ts || local != tt.local {
+			t.Errorf("TilesetForGID(%#x) = %d, %d, want %d, %d", tt.gid, ts, local, tt.ts, tt.local)
+		}
+	}
+}
+
+const testTSX = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset name="tiles" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+ <image source="../img/tiles.png" trans="ff00ff" width="32" height="32"/>
+ <tile id="1" type="WALL">
+  <properties><property name="solid" type="bool" value="true"/></properties>
+  <animation><frame tileid="1" duration="100"/><frame tileid="2" duration="100"/></animation>
+ </tile>
+</tileset>`
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map orientation="orthogonal" width="3" height="2" tilewidth="16" tileheight="16" infinite="0">
+ <properties><property name="city" value="NYC"/></properties>
+ <tileset firstgid="1" source="sets/tiles.tsx"/>
+ <layer id="1" name="Ground" width="3" height="2">
+  <data encoding="csv">1,2,3,
+4,0,1073741826</data>
+ </layer>
+ <objectgroup id="2" name="Spawns">
+  <object id="1" name="PLAYER_START" x="8" y="24"><point/></object>
+  <object id="2" name="ZONE" x="0" y="0"><polygon points="0,0 16,0 16,16"/></object>
+ </objectgroup>
+ <layer id="3" name="Top" width="3" height="2" visible="0">
+  <data><tile gid="0"/><tile/><tile gid="2"/><tile/><tile/><tile/></data>
+ </layer>
+</map>`
+
+func TestLoadMapFSTMX(t *testing.T) {
+	fsys := fstest.MapFS{
+		"maps/city.tmx":       {Data: []byte(testTMX)},
+		"maps/sets/tiles.tsx": {Data: []byte(testTSX)},
+	}
+	m, err := LoadMapFS(fsys, "maps/city.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.Tilesets) != 1 {
+		t.Fatalf("got %d tilesets, want 1", len(m.Tilesets))
+	}
+	ts := m.Tilesets[0]
+	if ts.FirstGID != 1 || ts.Image != "maps/img/tiles.png" || ts.Columns != 2 || ts.TransparentColor != "#ff00ff" {
+		t.Errorf("tileset = firstgid %d image %q columns %d trans %q", ts.FirstGID, ts.Image, ts.Columns, ts.TransparentColor)
+	}
+	if len(ts.Tiles) != 1 || ts.Tiles[0].Type != "WALL" || len(ts.Tiles[0].Animation) != 2 {
+		t.Errorf("tile defs = %+v", ts.Tiles)
+	}
+
+	// Layers stay in document order, object groups included
+	var names []string
+	for _, l := range m.Layers {
+		names = append(names, l.Type+":"+l.Name)
+	}
+	if want := []string{"tilelayer:Ground", "objectgroup:Spawns", "tilelayer:Top"}; !slices.Equal(names, want) {
+		t.Errorf("layers = %v, want %v", names, want)
+	}
+
+	ground := m.FindTileLayer("Ground")
+	if want := []uint32{1, 2, 3, 4, 0, flipV | 2}; !slices.Equal(ground.Data, want) {
+		t.Errorf("ground = %v, want %v", ground.Data, want)
+	}
+	if got := m.TileAt(ground, 2, 1); got != flipV|2 {
+		t.Errorf("TileAt(2,1) = %#x, keeps the flip bits", got)
+	}
+	if got := m.TileAt(ground, 3, 0); got != 0 {
+		t.Errorf("TileAt off the map = %d, want 0", got)
+	}
+	if m.Layers[2].Visible {
+		t.Error("Top has visible=0")
+	}
+
+	spawns := m.Layers[1].Objects
+	if !spawns[0].Point || spawns[0].X != 8 || spawns[0].Y != 24 {
+		t.Errorf("point object = %+v", spawns[0])
+	}
+	if len(spawns[1].Polygon) != 3 || spawns[1].Polygon[1] != (Point{X: 16, Y: 0}) {
+		t.Errorf("polygon = %v", spawns[1].Polygon)
+	}
+}
+
+func TestConvertXMLProperties(t *testing.T) {
+	props, err := convertXMLProperties([]xmlProperty{
+		{Name: "name", Value: "ferry"},
+		{Name: "speed", Type: "float", Value: "1.5"},
+		{Name: "lanes", Type: "int", Value: "2"},
+		{Name: "empty", Type: "int"},
+		{Name: "oneway", Type: "bool", Value: "true"},
+		{Name: "note", Text: "\nmulti\nline\n"},
+		{Name: "spawn", Type: "class", Members: []xmlProperty{{Name: "x", Type: "int", Value: "4"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"name":   "ferry",
+		"speed":  1.5,
+		"lanes":  2.0,
+		"empty":  0.0,
+		"oneway": true,
+		"note":   "\nmulti\nline\n",
+	}
+	for _, p := range props {
+		if w, ok := want[p.Name]; ok && p.Value != w {
+			t.Errorf("%s = %#v, want %#v", p.Name, p.Value, w)
+		}
+	}
+	class, ok := props[len(props)-1].Value.(Properties)
+	if !ok || len(class) != 1 || class[0].Value != 4.0 {
+		t.Errorf("class members = %#v", props[len(props)-1].Value)
+	}
+
+	if _, err := convertXMLProperties([]xmlProperty{{Name: "n", Type: "int", Value: "two"}}); err == nil {
+		t.Error("bad int should fail")
+	}
+}
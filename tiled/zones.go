@@ -0,0 +1,148 @@
+package tiled
+
+// Object-layer shapes mean one of two things, decided by the object's class (type) in Tiled:
+//   - "collider" (or property solid=true): a static wall, baked into the CollisionGrid
+//   - "trigger": a named zone the game gets enter/exit events for (park shortcut, construction...)
+//
+// Anything else (points, spawns, checkpoints) is left to ExtractSpawns and friends.
+const (
+	ObjectCollider = "collider"
+	ObjectTrigger  = "trigger"
+)
+
+// Zone is a trigger area from an object layer, in map pixels
+type Zone struct {
+	ID         int
+	Name       string
+	Layer      string // object layer it was drawn on
+	Shape      Shape
+	Properties Properties
+}
+
+// isObjectCollider reports whether an object-layer object should block movement
+func isObjectCollider(o *Object) bool {
+	if o.Type == ObjectCollider {
+		return true
+	}
+	return o.Properties.Bool("solid", false)
+}
+
+// walkObjects calls fn for every object on every visible object layer
+func walkObjects(layers []Layer, fn func(layer *Layer, obj *Object)) {
+	for i := range layers {
+		layer := &layers[i]
+		if !layer.Visible {
+			continue
+		}
+
+		switch layer.Type {
+		case "group":
+			walkObjects(layer.Layers, fn)
+		case "objectgroup":
+			for j := range layer.Objects {
+				fn(layer, &layer.Objects[j])
+			}
+		}
+	}
+}
+
+// ExtractZones returns every "trigger" rectangle, ellipse and polygon in the map
+func ExtractZones(m *Map) []Zone {
+	var zones []Zone
+	walkObjects(m.Layers, func(layer *Layer, obj *Object) {
+		if obj.Type != ObjectTrigger {
+			return
+		}
+		shape, ok := ObjectShape(obj)
+		if !ok {
+			return
+		}
+		zones = append(zones, Zone{
+			ID:         obj.ID,
+			Name:       obj.Name,
+			Layer:      layer.Name,
+			Shape:      shape,
+			Properties: obj.Properties,
+		})
+	})
+	return zones
+}
+
+// ZoneHandler is called with the zone that was entered or left
+type ZoneHandler func(z *Zone)
+
+// Triggers keeps track of which zones one mover (the player) is inside
+// and fires the enter/exit handlers when that changes.
+type Triggers struct {
+	Zones []Zone
+
+	// World pixels per map pixel, same as CollisionGrid.Scale
+	Scale float64
+
+	inside  []bool
+	onEnter map[string][]ZoneHandler
+	onExit  map[string][]ZoneHandler
+}
+
+// NewTriggers collects the map's trigger zones
+func NewTriggers(m *Map) *Triggers {
+	zones := ExtractZones(m)
+	return &Triggers{
+		Zones:   zones,
+		Scale:   1,
+		inside:  make([]bool, len(zones)),
+		onEnter: map[string][]ZoneHandler{},
+		onExit:  map[string][]ZoneHandler{},
+	}
+}
+
+// OnEnter subscribes to entering zones with this name ("" for every zone)
+func (t *Triggers) OnEnter(name string, fn ZoneHandler) {
+	t.onEnter[name] = append(t.onEnter[name], fn)
+}
+
+// OnExit subscribes to leaving zones with this name ("" for every zone)
+func (t *Triggers) OnExit(name string, fn ZoneHandler) {
+	t.onExit[name] = append(t.onExit[name], fn)
+}
+
+// Update checks a world-space rectangle against every zone and fires handlers for changes
+func (t *Triggers) Update(x, y, w, h float64) {
+	s := t.Scale
+	if s <= 0 {
+		s = 1
+	}
+	x, y, w, h = x/s, y/s, w/s, h/s
+
+	for i := range t.Zones {
+		z := &t.Zones[i]
+		now := z.Shape.OverlapsRect(x, y, w, h)
+		if now == t.inside[i] {
+			continue
+		}
+		t.inside[i] = now
+
+		handlers := t.onExit
+		if now {
+			handlers = t.onEnter
+		}
+		if z.Name != "" {
+			for _, fn := range handlers[z.Name] {
+				fn(z)
+			}
+		}
+		for _, fn := range handlers[""] {
+			fn(z)
+		}
+	}
+}
+
+// Inside reports whether the mover is currently in a zone with this name
+func (t *Triggers) Inside(name string) bool {
+	for i := range t.Zones {
+		if t.inside[i] && t.Zones[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
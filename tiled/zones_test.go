@@ -0,0 +1,100 @@
+package tiled
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// zoneMap is a 10x10 map of 16px tiles with walls and zones drawn on object layers
+func zoneMap() *Map {
+	return &Map{
+		Width: 10, Height: 10, TileWidth: 16, TileHeight: 16,
+		Layers: []Layer{
+			{Name: "Zones", Type: "objectgroup", Visible: true, Objects: []Object{
+				{ID: 1, Name: "park", Type: ObjectTrigger, X: 16, Y: 16, Width: 32, Height: 32,
+					Properties: []ObjectProperty{{Name: "surface", Type: "string", Value: "grass"}}},
+				{ID: 2, Name: "spot", Type: ObjectTrigger, X: 80, Y: 80, Point: true}, // no area, no zone
+				{ID: 3, Name: "fence", Type: ObjectCollider, X: 64, Y: 0, Width: 4, Height: 48},
+				{ID: 4, Name: "barrier", X: 0, Y: 96, Polygon: []Point{{0, 0}, {32, 0}, {0, 16}},
+					Properties: []ObjectProperty{{Name: "solid", Type: "bool", Value: true}}},
+				{ID: 5, Name: "PLAYER_START", X: 8, Y: 8, Point: true},
+			}},
+			{Name: "More", Type: "group", Visible: true, Layers: []Layer{
+				{Name: "Works", Type: "objectgroup", Visible: true, Objects: []Object{
+					{ID: 6, Name: "construction", Type: ObjectTrigger, X: 96, Y: 16, Width: 32, Height: 32, Ellipse: true},
+				}},
+			}},
+			{Name: "Off", Type: "objectgroup", Objects: []Object{
+				{ID: 7, Name: "hidden", Type: ObjectTrigger, X: 0, Y: 0, Width: 160, Height: 160},
+				{ID: 8, Name: "hidden wall", Type: ObjectCollider, X: 0, Y: 0, Width: 160, Height: 160},
+			}},
+		},
+	}
+}
+
+func TestExtractZones(t *testing.T) {
+	zones := ExtractZones(zoneMap())
+	var got []string
+	for _, z := range zones {
+		got = append(got, fmt.Sprintf("%d %s on %s", z.ID, z.Name, z.Layer))
+	}
+	if want := []string{"1 park on Zones", "6 construction on Works"}; !slices.Equal(got, want) {
+		t.Fatalf("zones = %v, want %v", got, want)
+	}
+	if v, _ := findProperty(zones[0].Properties, "surface"); v != "grass" {
+		t.Errorf("park surface = %v, the zone should keep its properties", v)
+	}
+}
+
+func TestObjectColliders(t *testing.T) {
+	g := BuildCollisionGrid(zoneMap())
+	points := []struct {
+		x, y float64
+		want bool
+	}{
+		{66, 20, true},   // fence
+		{70, 20, false},  // just past it
+		{4, 100, true},   // barrier, by its solid property
+		{28, 108, false}, // the barrier's open corner
+		{24, 24, false},  // triggers don't block
+		{150, 150, false},
+	}
+	for _, p := range points {
+		if got := g.PointCollides(p.x, p.y); got != p.want {
+			t.Errorf("PointCollides(%v, %v) = %v, want %v", p.x, p.y, got, p.want)
+		}
+	}
+}
+
+func TestTriggers(t *testing.T) {
+	tr := NewTriggers(zoneMap())
+	tr.Scale = 2 // movers are in world pixels
+
+	var log []string
+	tr.OnEnter("park", func(z *Zone) { log = append(log, "enter park") })
+	tr.OnExit("park", func(z *Zone) { log = append(log, "exit park") })
+	tr.OnEnter("", func(z *Zone) { log = append(log, "enter any "+z.Name) })
+
+	steps := []struct {
+		x, y    float64
+		inPark  bool
+		wantLog []string
+	}{
+		{0, 0, false, nil},
+		{40, 40, true, []string{"enter park", "enter any park"}},
+		{60, 60, true, nil}, // still inside, nothing new
+		{200, 40, false, []string{"exit park", "enter any construction"}},
+		{0, 0, false, nil},
+	}
+	for i, s := range steps {
+		log = nil
+		tr.Update(s.x, s.y, 8, 8)
+		if !slices.Equal(log, s.wantLog) {
+			t.Errorf("step %d: events %v, want %v", i, log, s.wantLog)
+		}
+		if tr.Inside("park") != s.inPark {
+			t.Errorf("step %d: Inside(park) = %v", i, !s.inPark)
+		}
+	}
+}